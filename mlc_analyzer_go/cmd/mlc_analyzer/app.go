@@ -4,20 +4,50 @@ import (
 	"context"
 	"fmt"
 	"log"
-	// "strconv" // Not directly used in App struct methods here
-	// "strings" // Not directly used in App struct methods here
-	// "path/filepath" // Not directly used in App struct methods here
-
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	goruntime "runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/user/mlc_analyzer_go/internal/analysis"
+	"github.com/user/mlc_analyzer_go/internal/exporter"
 	"github.com/user/mlc_analyzer_go/internal/parser"
 	"github.com/user/mlc_analyzer_go/internal/report"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// sessionDateRe matches a YYYY-MM-DD or YYYYMMDD date embedded in a QA
+// session's CSV filename, used by HandleBatchGenerateReport to order
+// sessions chronologically.
+var sessionDateRe = regexp.MustCompile(`\d{4}-\d{2}-\d{2}|\d{8}`)
+
+// sessionDateFromFilename extracts a session date from name, falling back to
+// fallback (typically the file's modification time) if none is found.
+func sessionDateFromFilename(name string, fallback time.Time) time.Time {
+	match := sessionDateRe.FindString(name)
+	if match == "" {
+		return fallback
+	}
+	if t, err := time.Parse("2006-01-02", match); err == nil {
+		return t
+	}
+	if t, err := time.Parse("20060102", match); err == nil {
+		return t
+	}
+	return fallback
+}
+
 // App struct
 type App struct {
-	ctx context.Context
+	ctx              context.Context
+	metricsCollector *exporter.Collector
+	lastResults      *analysis.AnalysisResults // Set after HandleGenerateReport's analysis step, for HandleJoinLeafInfo
 }
 
 // NewApp creates a new App application struct
@@ -50,8 +80,174 @@ func (a *App) clearLog() {
     }
 }
 
-// HandleGenerateReport is called from the frontend to start the report generation process
-func (a *App) HandleGenerateReport(csvFilePath string, pdfFilePath string, toleranceVal float64) (string, error) {
+// StartMetricsServer starts a Prometheus "/metrics" HTTP endpoint on port,
+// exposing the QA metrics of the latest HandleGenerateReport analysis run
+// after it starts. Analyses run by the other handlers (NPZ export, composite
+// report, batch mode) do not update these metrics.
+// Calling it more than once replaces the collector, so only the most
+// recently started server's results are exported.
+func (a *App) StartMetricsServer(port int) (string, error) {
+	a.metricsCollector = exporter.NewCollector()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", a.metricsCollector.Handler())
+
+	addr := fmt.Sprintf(":%d", port)
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			a.sendStatus(fmt.Sprintf("Metrics server stopped: %v", err))
+		}
+	}()
+
+	successMsg := fmt.Sprintf("Metrics server listening on %s/metrics", addr)
+	a.sendStatus(successMsg)
+	return successMsg, nil
+}
+
+// HandleExportNPY parses csvFilePath and writes its leaf position data to
+// npyFilePath as a NumPy .npy array (plus a "<npyFilePath>.banks.json"
+// manifest), so the raw data can be loaded directly in Python without
+// re-running the CSV parser.
+func (a *App) HandleExportNPY(csvFilePath string, npyFilePath string) (string, error) {
+	a.sendStatus(fmt.Sprintf("Exporting NPY: CSV=[%s] -> NPY=[%s]", csvFilePath, npyFilePath))
+
+	parsedData, err := parser.ParseMLCData(csvFilePath)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing CSV: %v", err)
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	if err := parsedData.WriteNPY(npyFilePath); err != nil {
+		errMsg := fmt.Sprintf("Error writing NPY: %v", err)
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	successMsg := fmt.Sprintf("NPY export successfully generated: %s", npyFilePath)
+	a.sendStatus(successMsg)
+	return successMsg, nil
+}
+
+// HandleExportNPZ parses csvFilePath, analyzes it, and writes the per-leaf
+// statistics to npzFilePath as a zip bundle of .npy arrays.
+func (a *App) HandleExportNPZ(csvFilePath string, npzFilePath string, toleranceVal float64) (string, error) {
+	a.sendStatus(fmt.Sprintf("Exporting NPZ: CSV=[%s] -> NPZ=[%s]", csvFilePath, npzFilePath))
+
+	parsedData, err := parser.ParseMLCData(csvFilePath)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing CSV: %v", err)
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	analysisResults, err := analysis.AnalyzeMLCData(parsedData, toleranceVal)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error analyzing data: %v", err)
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	if err := analysisResults.WriteNPZ(npzFilePath); err != nil {
+		errMsg := fmt.Sprintf("Error writing NPZ: %v", err)
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	successMsg := fmt.Sprintf("NPZ export successfully generated: %s", npzFilePath)
+	a.sendStatus(successMsg)
+	return successMsg, nil
+}
+
+// HandleAnalyzePCA parses csvFilePath and runs a principal-component
+// analysis of leaf-position drift across runs, returning the top
+// `components` components for the frontend to chart.
+func (a *App) HandleAnalyzePCA(csvFilePath string, components int) (*analysis.PCAResult, error) {
+	a.sendStatus(fmt.Sprintf("Running PCA: CSV=[%s], components=%d", csvFilePath, components))
+
+	parsedData, err := parser.ParseMLCData(csvFilePath)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing CSV: %v", err)
+		a.sendStatus(errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	pcaResult, err := analysis.AnalyzePCA(parsedData, components)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error running PCA: %v", err)
+		a.sendStatus(errMsg)
+		return nil, fmt.Errorf(errMsg)
+	}
+
+	a.sendStatus("PCA complete.")
+	return pcaResult, nil
+}
+
+// HandleGenerateCompositeReport parses and analyzes csvFilePath, then renders
+// the four-row composite QA sheet (see report.CreateCompositeReport) to
+// imageFilePath as a single image in the format imageFilePath's extension
+// implies (.png, .svg, or .pdf).
+func (a *App) HandleGenerateCompositeReport(csvFilePath string, imageFilePath string, toleranceVal float64) (string, error) {
+	a.sendStatus(fmt.Sprintf("Generating composite report: CSV=[%s] -> Image=[%s]", csvFilePath, imageFilePath))
+
+	parsedData, err := parser.ParseMLCData(csvFilePath)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error parsing CSV: %v", err)
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	analysisResults, err := analysis.AnalyzeMLCData(parsedData, toleranceVal)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error analyzing data: %v", err)
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	format := report.PlotFormatPNG
+	switch strings.ToLower(filepath.Ext(imageFilePath)) {
+	case ".svg":
+		format = report.PlotFormatSVG
+	case ".pdf":
+		format = report.PlotFormatPDF
+	}
+
+	imgBytes, err := report.CreateCompositeReport(analysisResults, report.CompositeReportLayout{ToleranceMM: toleranceVal, Format: format})
+	if err != nil {
+		errMsg := fmt.Sprintf("Error building composite report: %v", err)
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	if err := os.WriteFile(imageFilePath, imgBytes, 0644); err != nil {
+		errMsg := fmt.Sprintf("Error writing composite report: %v", err)
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	successMsg := fmt.Sprintf("Composite report successfully generated: %s", imageFilePath)
+	a.sendStatus(successMsg)
+	return successMsg, nil
+}
+
+// HandleJoinLeafInfo returns the most recent HandleGenerateReport run's
+// rankings, each joined with the requested subset of leaf attributes from
+// that run's AnalysisResults.Info (see analysis.AnalysisResults.JoinInfo).
+func (a *App) HandleJoinLeafInfo(selector []string) ([]analysis.EnrichedRankedLeafInfo, error) {
+	if a.lastResults == nil {
+		return nil, fmt.Errorf("no analysis results available yet; run a report first")
+	}
+	return a.lastResults.JoinInfo(selector), nil
+}
+
+// HandleGenerateReport is called from the frontend to start the report generation process.
+// institution, machineID, and operator are optional provenance recorded in
+// the PDF's per-page header/footer (see report.ReportMeta); pass "" for any
+// that aren't applicable. protection is optional password-protection and
+// permission restrictions for the generated PDF (see report.ProtectionOptions);
+// pass nil for an unprotected report. pageConfig's zero value produces the
+// report's default Letter-landscape layout.
+func (a *App) HandleGenerateReport(csvFilePath string, pdfFilePath string, toleranceVal float64, institution string, machineID string, operator string, protection *report.ProtectionOptions, pageConfig report.PageConfig) (string, error) {
     // This method now returns (string, error) to satisfy Wails binding,
     // but primary communication is via events for async operations.
     // The returned string could be an immediate ack, error for parameter validation.
@@ -103,6 +299,25 @@ func (a *App) HandleGenerateReport(csvFilePath string, pdfFilePath string, toler
 			a.sendStatus("Analysis Warnings/Errors:")
 			for _, e := range analysisResults.AnalysisErrors { a.sendStatus(fmt.Sprintf("- %s", e)) }
 		}
+		if a.metricsCollector != nil {
+			a.metricsCollector.Update(analysisResults)
+		}
+		a.lastResults = analysisResults
+
+		// analysisResults.Info already has each leaf's bank_side/nominal_setpoint
+		// from AnalyzeMLCData; merge in the provenance only this handler knows
+		// about (machine ID, QA session timestamp) so HandleJoinLeafInfo can
+		// return it too.
+		generatedAt := time.Now()
+		for _, res := range analysisResults.Results {
+			attrs := make(map[string]string)
+			for k, v := range analysisResults.Info.Get(res.BankName, res.LeafID) {
+				attrs[k] = v
+			}
+			attrs["machine_id"] = machineID
+			attrs["timestamp"] = generatedAt.Format(time.RFC3339)
+			analysisResults.Info.Set(res.BankName, res.LeafID, attrs)
+		}
 
 		a.sendStatus("Generating plots...")
 		plotImages := make(map[string][]byte)
@@ -111,6 +326,8 @@ func (a *App) HandleGenerateReport(csvFilePath string, pdfFilePath string, toler
             {Name: "line_reproducibility_Left", Type: "line", BankFilter: "Left", Title: "Leaf Reproducibility (Left Bank)", ValueCol: "reproducibility"},
             {Name: "line_deviation_Right", Type: "line", BankFilter: "Right", Title: "Mean Leaf Deviation (Right Bank)", ValueCol: "deviation"},
             {Name: "line_reproducibility_Right", Type: "line", BankFilter: "Right", Title: "Leaf Reproducibility (Right Bank)", ValueCol: "reproducibility"},
+            {Name: "boxplot_Left", Type: "line", BankFilter: "Left", Title: "Per-Leaf Reproducibility (Box Plot, Left Bank)", ValueCol: "boxplot"},
+            {Name: "boxplot_Right", Type: "line", BankFilter: "Right", Title: "Per-Leaf Reproducibility (Box Plot, Right Bank)", ValueCol: "boxplot"},
             {Name: "heatmap_deviation", Type: "heatmap", Title: "Heatmap of Mean Leaf Deviation (mm)", ValueCol: "Deviation (mm)"},
             {Name: "heatmap_stddev", Type: "heatmap", Title: "Heatmap of Leaf Reproducibility (Std Dev mm)", ValueCol: "Std Dev (mm)"},
             {Name: "heatmap_range", Type: "heatmap", Title: "Heatmap of Leaf Positional Range (mm)", ValueCol: "Range (mm)"},
@@ -120,9 +337,9 @@ func (a *App) HandleGenerateReport(csvFilePath string, pdfFilePath string, toler
 			var imgBytes []byte
 			var errPlt error
 			if pc.Type == "line" {
-				imgBytes, errPlt = report.CreateLinePlot(analysisResults, pc.ValueCol, pc.BankFilter, toleranceVal)
+				imgBytes, errPlt = report.CreateLinePlot(analysisResults, pc.ValueCol, pc.BankFilter, toleranceVal, report.PaletteDefault, report.PlotFormatPNG)
 			} else if pc.Type == "heatmap" {
-				imgBytes, errPlt = report.CreateHeatmapPlot(analysisResults, pc.ValueCol, pc.Title)
+				imgBytes, errPlt = report.CreateHeatmapPlot(analysisResults, pc.ValueCol, pc.Title, "", report.PlotFormatPNG)
 			}
 
 			if errPlt != nil {
@@ -134,7 +351,17 @@ func (a *App) HandleGenerateReport(csvFilePath string, pdfFilePath string, toler
 		a.sendStatus("Plot generation complete.")
 
 		a.sendStatus(fmt.Sprintf("Generating PDF: %s...", pdfFilePath))
-		err = report.BuildPDFReport(pdfFilePath, analysisResults, parsedData.NumRuns, toleranceVal, plotImages)
+		meta := report.ReportMeta{
+			Institution:    institution,
+			MachineID:      machineID,
+			Operator:       operator,
+			GeneratedAt:    generatedAt,
+			SourceFileName: filepath.Base(csvFilePath),
+		}
+		if protection != nil {
+			a.sendStatus(fmt.Sprintf("PDF protection enabled: print=%v copy=%v modify=%v", protection.AllowPrint, protection.AllowCopy, protection.AllowModify))
+		}
+		err = report.BuildPDFReport(pdfFilePath, analysisResults, parsedData.NumRuns, toleranceVal, plotImages, meta, protection, pageConfig)
 		if err != nil {
 			errMsg := fmt.Sprintf("Error generating PDF report: %v", err)
 			a.sendStatus(errMsg)
@@ -148,3 +375,112 @@ func (a *App) HandleGenerateReport(csvFilePath string, pdfFilePath string, toler
 
 	return "Report generation started in background.", nil
 }
+
+// HandleBatchGenerateReport parses and analyzes every *.csv file in csvDir in
+// parallel (a worker per CPU), treating each as an independent dated QA
+// session, then combines them into a single longitudinal trend report at
+// outputPdf via report.BuildTrendReport. Each session's date is parsed from
+// its filename (YYYY-MM-DD or YYYYMMDD), falling back to the file's
+// modification time.
+func (a *App) HandleBatchGenerateReport(csvDir string, outputPdf string, toleranceVal float64) (string, error) {
+	a.clearLog()
+	a.sendStatus(fmt.Sprintf("Batch request: Dir=[%s], PDF=[%s], Tol=%.2f", csvDir, outputPdf, toleranceVal))
+
+	entries, err := os.ReadDir(csvDir)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error reading directory: %v", err)
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	var csvFiles []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".csv") {
+			continue
+		}
+		csvFiles = append(csvFiles, filepath.Join(csvDir, entry.Name()))
+	}
+	if len(csvFiles) == 0 {
+		errMsg := fmt.Sprintf("No CSV files found in %s", csvDir)
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	workers := goruntime.NumCPU()
+	jobs := make(chan string, len(csvFiles))
+	sessionsCh := make(chan report.DatedAnalysis, len(csvFiles))
+	var errCount int32
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for csvPath := range jobs {
+				a.sendStatus(fmt.Sprintf("Processing: %s", csvPath))
+
+				parsedData, err := parser.ParseMLCData(csvPath)
+				if err != nil {
+					a.sendStatus(fmt.Sprintf("Error parsing %s: %v", csvPath, err))
+					atomic.AddInt32(&errCount, 1)
+					continue
+				}
+				analysisResults, err := analysis.AnalyzeMLCData(parsedData, toleranceVal)
+				if err != nil {
+					a.sendStatus(fmt.Sprintf("Error analyzing %s: %v", csvPath, err))
+					atomic.AddInt32(&errCount, 1)
+					continue
+				}
+
+				fallbackDate := time.Now()
+				if info, statErr := os.Stat(csvPath); statErr == nil {
+					fallbackDate = info.ModTime()
+				}
+
+				sessionsCh <- report.DatedAnalysis{
+					Date:    sessionDateFromFilename(filepath.Base(csvPath), fallbackDate),
+					Source:  filepath.Base(csvPath),
+					Results: analysisResults,
+				}
+			}
+		}()
+	}
+	for _, csvPath := range csvFiles {
+		jobs <- csvPath
+	}
+	close(jobs)
+	wg.Wait()
+	close(sessionsCh)
+
+	sessions := make([]report.DatedAnalysis, 0, len(csvFiles))
+	for session := range sessionsCh {
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Date.Before(sessions[j].Date) })
+
+	if len(sessions) == 0 {
+		errMsg := "No sessions could be analyzed."
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+	if errCount > 0 {
+		a.sendStatus(fmt.Sprintf("%d of %d files failed to parse/analyze; continuing with the rest.", errCount, len(csvFiles)))
+	}
+
+	a.sendStatus(fmt.Sprintf("Analyzed %d of %d sessions. Building trend report...", len(sessions), len(csvFiles)))
+
+	meta := report.ReportMeta{
+		GeneratedAt:    time.Now(),
+		SourceFileName: filepath.Base(csvDir),
+	}
+	const minPersistentSessions = 3
+	if err := report.BuildTrendReport(outputPdf, sessions, toleranceVal, minPersistentSessions, meta); err != nil {
+		errMsg := fmt.Sprintf("Error building trend report: %v", err)
+		a.sendStatus(errMsg)
+		return "", fmt.Errorf(errMsg)
+	}
+
+	successMsg := fmt.Sprintf("Trend report successfully generated: %s", outputPdf)
+	a.sendStatus(successMsg)
+	return successMsg, nil
+}
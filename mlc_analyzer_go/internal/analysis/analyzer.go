@@ -65,8 +65,16 @@ func calculateRange(data []float64) float64 {
 }
 
 
-// AnalyzeMLCData performs statistical analysis on parsed MLC data.
+// AnalyzeMLCData performs statistical analysis on parsed MLC data using
+// DefaultAnalysisConfig for the chi-square tests.
 func AnalyzeMLCData(parsedData *parser.ParsedMLCData, toleranceMM float64) (*AnalysisResults, error) {
+	return AnalyzeMLCDataWithConfig(parsedData, toleranceMM, DefaultAnalysisConfig())
+}
+
+// AnalyzeMLCDataWithConfig performs statistical analysis on parsed MLC data,
+// including the chi-square goodness-of-fit and variance tests configured by
+// config (see AnalysisConfig).
+func AnalyzeMLCDataWithConfig(parsedData *parser.ParsedMLCData, toleranceMM float64, config AnalysisConfig) (*AnalysisResults, error) {
 	if parsedData == nil || len(parsedData.Data) == 0 {
 		return nil, fmt.Errorf("parsed data is nil or empty, cannot analyze")
 	}
@@ -77,6 +85,7 @@ func AnalyzeMLCData(parsedData *parser.ParsedMLCData, toleranceMM float64) (*Ana
 	allDeviations := []RankedLeafInfo{}
 	allStdDevs := []RankedLeafInfo{}
 	allRanges := []RankedLeafInfo{}
+	allChiSquare := []RankedLeafInfo{}
 
 	for _, bankName := range parsedData.BankNames {
 		bankSpecificData, ok := parsedData.Data[bankName]
@@ -91,6 +100,8 @@ func AnalyzeMLCData(parsedData *parser.ParsedMLCData, toleranceMM float64) (*Ana
 			continue
 		}
 
+		bankResults := make([]LeafAnalysisResult, 0, parser.NumLeaves)
+
 		for leafIdx := 0; leafIdx < parser.NumLeaves; leafIdx++ {
 			leafRunMeasurements := bankSpecificData[leafIdx] // This is a slice of measurements for this leaf across all runs
 
@@ -147,8 +158,22 @@ func AnalyzeMLCData(parsedData *parser.ParsedMLCData, toleranceMM float64) (*Ana
 			if !math.IsNaN(res.PositionalRange) {
 				allRanges = append(allRanges, RankedLeafInfo{LeafID: leafID, BankName: bankName, Value: res.PositionalRange})
 			}
-			results.Results = append(results.Results, res)
+			bankSide := "Left"
+			if leafIDPrefix == "R" {
+				bankSide = "Right"
+			}
+			results.Info.Set(bankName, leafID, map[string]string{
+				"bank_side":        bankSide,
+				"nominal_setpoint": fmt.Sprintf("%d", nominalSetpoint),
+			})
+
+			bankResults = append(bankResults, res)
 		}
+
+		// Pooled-variance chi-square tests need every leaf in the bank, so
+		// they run once the bank's leaf loop above has finished.
+		allChiSquare = append(allChiSquare, applyChiSquareTests(bankResults, config)...)
+		results.Results = append(results.Results, bankResults...)
 	}
 
 	// Sort the rankings
@@ -167,6 +192,11 @@ func AnalyzeMLCData(parsedData *parser.ParsedMLCData, toleranceMM float64) (*Ana
 	})
 	results.RankedByRange = allRanges
 
+	sort.Slice(allChiSquare, func(i, j int) bool {
+		return allChiSquare[i].Value < allChiSquare[j].Value // Ascending p-value: most anomalous first
+	})
+	results.RankedByChiSquare = allChiSquare
+
 	if len(results.Results) == 0 && len(parsedData.BankNames) > 0 {
 		results.AnalysisErrors = append(results.AnalysisErrors, "Analysis completed but produced no individual leaf results.")
 	}
@@ -0,0 +1,162 @@
+package analysis
+
+import "math"
+
+const (
+	maxGammaIterations = 200
+	gammaEpsilon       = 3e-14
+	gammaTiny          = 1e-300
+)
+
+// regularizedGammaP computes the regularized lower incomplete gamma function
+// P(a, x) = gamma(a, x) / Gamma(a), which is also the chi-square CDF once a
+// and x are scaled by 2 (see chiSquareCDF). It follows the standard split for
+// numerical stability: a series expansion for x < a+1, and a Lentz continued
+// fraction (evaluating the complementary Q(a,x) = 1-P(a,x)) for x >= a+1.
+func regularizedGammaP(a, x float64) float64 {
+	if a <= 0 || x < 0 {
+		return math.NaN()
+	}
+	if x == 0 {
+		return 0
+	}
+	if x < a+1 {
+		return gammaPSeries(a, x)
+	}
+	return 1 - gammaQContinuedFraction(a, x)
+}
+
+// gammaPSeries evaluates P(a,x) via its series expansion, valid for x < a+1.
+func gammaPSeries(a, x float64) float64 {
+	gln, _ := math.Lgamma(a)
+	ap := a
+	sum := 1.0 / a
+	del := sum
+	for i := 0; i < maxGammaIterations; i++ {
+		ap++
+		del *= x / ap
+		sum += del
+		if math.Abs(del) < math.Abs(sum)*gammaEpsilon {
+			break
+		}
+	}
+	return sum * math.Exp(-x+a*math.Log(x)-gln)
+}
+
+// gammaQContinuedFraction evaluates Q(a,x) = 1-P(a,x) via Lentz's algorithm
+// for the continued-fraction representation of the upper incomplete gamma
+// function, valid for x >= a+1.
+func gammaQContinuedFraction(a, x float64) float64 {
+	gln, _ := math.Lgamma(a)
+	b := x + 1 - a
+	c := 1 / gammaTiny
+	d := 1 / b
+	h := d
+	for i := 1; i <= maxGammaIterations; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < gammaTiny {
+			d = gammaTiny
+		}
+		c = b + an/c
+		if math.Abs(c) < gammaTiny {
+			c = gammaTiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < gammaEpsilon {
+			break
+		}
+	}
+	return math.Exp(-x+a*math.Log(x)-gln) * h
+}
+
+// chiSquareCDF returns P(X <= x) for a chi-square distribution with k degrees
+// of freedom, via the regularized lower incomplete gamma function P(k/2, x/2).
+func chiSquareCDF(x, k float64) float64 {
+	if k <= 0 {
+		return math.NaN()
+	}
+	return regularizedGammaP(k/2, x/2)
+}
+
+// chiSquareUpperTailPValue returns P(X >= x): the probability, under the null
+// hypothesis, of observing a chi-square statistic at least this extreme.
+func chiSquareUpperTailPValue(x, k float64) float64 {
+	cdf := chiSquareCDF(x, k)
+	if math.IsNaN(cdf) {
+		return math.NaN()
+	}
+	return 1 - cdf
+}
+
+// pooledStdDev estimates a bank-wide reference sigma by pooling the
+// per-leaf population variances (StdDev^2, see calculateStdDev) of every
+// leaf with at least two valid runs.
+func pooledStdDev(bankResults []LeafAnalysisResult) float64 {
+	var sumVar, count float64
+	for _, res := range bankResults {
+		if res.NumValidRuns < 2 || math.IsNaN(res.StdDev) {
+			continue
+		}
+		sumVar += res.StdDev * res.StdDev
+		count++
+	}
+	if count == 0 {
+		return math.NaN()
+	}
+	return math.Sqrt(sumVar / count)
+}
+
+// applyChiSquareTests runs the goodness-of-fit and variance chi-square tests
+// described on AnalysisConfig for every leaf in bankResults (mutating each
+// LeafAnalysisResult in place) and returns a RankedLeafInfo per tested leaf,
+// keyed on the more significant (smaller) of the two p-values.
+func applyChiSquareTests(bankResults []LeafAnalysisResult, config AnalysisConfig) []RankedLeafInfo {
+	referenceStdDev := config.ReferenceStdDev
+	if referenceStdDev <= 0 {
+		referenceStdDev = pooledStdDev(bankResults)
+	}
+
+	threshold := config.ChiSquarePValueThreshold
+	if threshold <= 0 {
+		threshold = DefaultChiSquarePValueThreshold
+	}
+
+	ranked := make([]RankedLeafInfo, 0, len(bankResults))
+	for i := range bankResults {
+		res := &bankResults[i]
+		res.ChiSquareGoodnessOfFitPValue = math.NaN()
+		res.ChiSquareVariancePValue = math.NaN()
+
+		if res.NumValidRuns < 2 || math.IsNaN(referenceStdDev) || referenceStdDev <= 0 {
+			continue
+		}
+		n := float64(res.NumValidRuns)
+		sigmaSq := referenceStdDev * referenceStdDev
+
+		sumSq := 0.0
+		for _, m := range res.Measurements {
+			d := m - float64(res.NominalSetpoint)
+			sumSq += d * d
+		}
+		res.ChiSquareGoodnessOfFitPValue = chiSquareUpperTailPValue(sumSq/sigmaSq, n)
+
+		// res.StdDev is the population std dev (divisor n, see calculateStdDev),
+		// so Sum((m-mean)^2) is n*StdDev^2, not (n-1)*StdDev^2; the chi-square
+		// distribution's degrees of freedom is still n-1.
+		varianceStat := n * res.StdDev * res.StdDev / sigmaSq
+		res.ChiSquareVariancePValue = chiSquareUpperTailPValue(varianceStat, n-1)
+
+		combined := res.ChiSquareGoodnessOfFitPValue
+		if res.ChiSquareVariancePValue < combined {
+			combined = res.ChiSquareVariancePValue
+		}
+		res.IsStatisticallyAnomalous = combined < threshold
+
+		ranked = append(ranked, RankedLeafInfo{LeafID: res.LeafID, BankName: res.BankName, Value: combined})
+	}
+	return ranked
+}
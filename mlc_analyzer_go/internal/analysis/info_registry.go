@@ -0,0 +1,89 @@
+package analysis
+
+// InfoRegistryKey identifies a single leaf for InfoRegistry lookups.
+type InfoRegistryKey struct {
+	BankName string
+	LeafID   string
+}
+
+// InfoRegistry stores identifying leaf attributes (bank side L/R, nominal
+// setpoint, physical leaf width, gantry angle of the measurement, machine
+// ID, QA session timestamp, ...) separately from LeafAnalysisResult and
+// RankedLeafInfo, following the Prometheus "info metric" pattern: the hot
+// analysis path stays lean, and callers join in whichever attributes they
+// need at query time via AnalysisResults.JoinInfo.
+type InfoRegistry struct {
+	attrs map[InfoRegistryKey]map[string]string
+}
+
+// NewInfoRegistry returns an empty InfoRegistry.
+func NewInfoRegistry() *InfoRegistry {
+	return &InfoRegistry{attrs: make(map[InfoRegistryKey]map[string]string)}
+}
+
+// Set records (or replaces) the attribute map for a single leaf.
+func (r *InfoRegistry) Set(bankName, leafID string, attrs map[string]string) {
+	key := InfoRegistryKey{BankName: bankName, LeafID: leafID}
+	copied := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		copied[k] = v
+	}
+	r.attrs[key] = copied
+}
+
+// Get returns the attribute map recorded for (bankName, leafID), or nil if
+// none was ever Set.
+func (r *InfoRegistry) Get(bankName, leafID string) map[string]string {
+	return r.attrs[InfoRegistryKey{BankName: bankName, LeafID: leafID}]
+}
+
+// EnrichedRankedLeafInfo is a RankedLeafInfo joined with a subset of its
+// leaf's InfoRegistry attributes, tagged with which ranking it came from.
+type EnrichedRankedLeafInfo struct {
+	RankedLeafInfo
+	RankingSource string
+	Labels        map[string]string
+}
+
+// JoinInfo returns every entry across all of AnalysisResults' rankings
+// (RankedInaccurate, RankedImprecise, RankedByRange, RankedByChiSquare),
+// each joined with the subset of its leaf's Info attributes named in
+// selector. A leaf missing a requested attribute simply omits that key from
+// Labels rather than erroring, so callers can pass a superset of keys they
+// expect most leaves to have. This lets the Wails frontend render rich
+// tables without the analysis package having to hardcode every metadata
+// field on RankedLeafInfo itself.
+func (r *AnalysisResults) JoinInfo(selector []string) []EnrichedRankedLeafInfo {
+	if r.Info == nil {
+		r.Info = NewInfoRegistry()
+	}
+
+	rankings := []struct {
+		source string
+		list   []RankedLeafInfo
+	}{
+		{"inaccurate", r.RankedInaccurate},
+		{"imprecise", r.RankedImprecise},
+		{"range", r.RankedByRange},
+		{"chi_square", r.RankedByChiSquare},
+	}
+
+	enriched := make([]EnrichedRankedLeafInfo, 0)
+	for _, ranking := range rankings {
+		for _, entry := range ranking.list {
+			attrs := r.Info.Get(entry.BankName, entry.LeafID)
+			labels := make(map[string]string, len(selector))
+			for _, key := range selector {
+				if v, ok := attrs[key]; ok {
+					labels[key] = v
+				}
+			}
+			enriched = append(enriched, EnrichedRankedLeafInfo{
+				RankedLeafInfo: entry,
+				RankingSource:  ranking.source,
+				Labels:         labels,
+			})
+		}
+	}
+	return enriched
+}
@@ -1,7 +1,5 @@
 package analysis
 
-import "math"
-
 // LeafAnalysisResult holds the calculated statistics for a single leaf.
 type LeafAnalysisResult struct {
 	BankName          string
@@ -15,7 +13,43 @@ type LeafAnalysisResult struct {
 	Deviation         float64
 	PositionalRange   float64
 	IsOutOfTolerance  bool
-	Error             string // If any error occurred calculating stats for this leaf
+
+	// ChiSquareGoodnessOfFitPValue is the p-value of a chi-square test of the
+	// run-to-run measurements against normal(NominalSetpoint, referenceStdDev).
+	// NaN when NumValidRuns < 2.
+	ChiSquareGoodnessOfFitPValue float64
+	// ChiSquareVariancePValue is the p-value of a chi-square test of variance,
+	// n*StdDev^2/referenceStdDev^2 (StdDev is the population std dev, so this
+	// is Sum((m-mean)^2)/referenceStdDev^2) against chi-square(n-1). NaN when
+	// NumValidRuns < 2.
+	ChiSquareVariancePValue float64
+	// IsStatisticallyAnomalous is true when the smaller of the two chi-square
+	// p-values above falls below AnalysisConfig.ChiSquarePValueThreshold.
+	IsStatisticallyAnomalous bool
+
+	Error string // If any error occurred calculating stats for this leaf
+}
+
+// AnalysisConfig configures the optional statistical tests layered on top of
+// AnalyzeMLCData's core deviation/std-dev/range computation.
+type AnalysisConfig struct {
+	// ChiSquarePValueThreshold marks a leaf as IsStatisticallyAnomalous once
+	// its chi-square p-value drops below this. Zero/negative falls back to
+	// DefaultChiSquarePValueThreshold.
+	ChiSquarePValueThreshold float64
+	// ReferenceStdDev is the machine-spec sigma the chi-square tests check
+	// each leaf against. Zero/negative estimates it from the bank's own
+	// pooled per-leaf StdDev instead.
+	ReferenceStdDev float64
+}
+
+// DefaultChiSquarePValueThreshold is the ChiSquarePValueThreshold used by
+// DefaultAnalysisConfig.
+const DefaultChiSquarePValueThreshold = 0.05
+
+// DefaultAnalysisConfig returns the AnalysisConfig used by AnalyzeMLCData.
+func DefaultAnalysisConfig() AnalysisConfig {
+	return AnalysisConfig{ChiSquarePValueThreshold: DefaultChiSquarePValueThreshold}
 }
 
 // RankedLeafInfo is used for ranking leaves by different criteria.
@@ -31,7 +65,16 @@ type AnalysisResults struct {
 	RankedInaccurate  []RankedLeafInfo // Sorted by absolute deviation, descending
 	RankedImprecise   []RankedLeafInfo // Sorted by standard deviation, descending
 	RankedByRange     []RankedLeafInfo // Sorted by positional range, descending
-	AnalysisErrors    []string
+	RankedByChiSquare []RankedLeafInfo // Sorted by chi-square p-value, ascending (most anomalous first)
+	// PCA holds the result of a prior AnalyzePCA call, or nil if PCA has not
+	// been run for these results. It is never populated by AnalyzeMLCData
+	// itself, since it is comparatively expensive and not every caller wants it.
+	PCA *PCAResult
+	// Info holds identifying leaf attributes (bank side, gantry angle,
+	// machine ID, ...) joined in at query time by JoinInfo, keeping
+	// LeafAnalysisResult itself lean. Lazily created by JoinInfo if nil.
+	Info           *InfoRegistry
+	AnalysisErrors []string
 }
 
 func NewAnalysisResults() *AnalysisResults {
@@ -40,6 +83,8 @@ func NewAnalysisResults() *AnalysisResults {
 		RankedInaccurate:  make([]RankedLeafInfo, 0),
 		RankedImprecise:   make([]RankedLeafInfo, 0),
 		RankedByRange:     make([]RankedLeafInfo, 0),
+		RankedByChiSquare: make([]RankedLeafInfo, 0),
+		Info:              NewInfoRegistry(),
 		AnalysisErrors:    make([]string, 0),
 	}
 }
@@ -0,0 +1,113 @@
+package analysis
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LeafManifestEntry records which leaf each entry in WriteNPZ's per-leaf stat
+// arrays corresponds to, in array order.
+type LeafManifestEntry struct {
+	LeafID          string `json:"leaf_id"`
+	BankName        string `json:"bank_name"`
+	LeafIndex       int    `json:"leaf_index"`
+	NominalSetpoint int    `json:"nominal_setpoint"`
+}
+
+// npyBytes encodes a 1-D float64 array as a complete .npy file (little-endian,
+// header padded to a 64-byte boundary), matching parser.WriteNPY's format so
+// the two exports can be loaded the same way in Python.
+func npyBytes(data []float64) []byte {
+	dict := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%d,), }", len(data))
+
+	const preambleLen = 10
+	padded := preambleLen + len(dict) + 1
+	padLen := (64 - padded%64) % 64
+	for i := 0; i < padLen; i++ {
+		dict += " "
+	}
+	dict += "\n"
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("\x93NUMPY")
+	buf.WriteByte(1) // major version
+	buf.WriteByte(0) // minor version
+	binary.Write(buf, binary.LittleEndian, uint16(len(dict)))
+	buf.WriteString(dict)
+	binary.Write(buf, binary.LittleEndian, data)
+	return buf.Bytes()
+}
+
+// WriteNPZ serializes per-leaf statistics (mean, stddev, deviation, range,
+// is_out_of_tolerance) as a zip bundle of .npy arrays, one per statistic, in
+// the order Results were produced by AnalyzeMLCData. A "leaves.json" manifest
+// entry in the zip records which leaf each array position corresponds to.
+func (r *AnalysisResults) WriteNPZ(path string) error {
+	if len(r.Results) == 0 {
+		return fmt.Errorf("no analysis results to export")
+	}
+
+	n := len(r.Results)
+	mean := make([]float64, n)
+	stddev := make([]float64, n)
+	deviation := make([]float64, n)
+	positionalRange := make([]float64, n)
+	isOutOfTolerance := make([]float64, n)
+	manifest := make([]LeafManifestEntry, n)
+
+	for i, res := range r.Results {
+		mean[i] = res.MeanPosition
+		stddev[i] = res.StdDev
+		deviation[i] = res.Deviation
+		positionalRange[i] = res.PositionalRange
+		if res.IsOutOfTolerance {
+			isOutOfTolerance[i] = 1
+		}
+		manifest[i] = LeafManifestEntry{
+			LeafID:          res.LeafID,
+			BankName:        res.BankName,
+			LeafIndex:       res.LeafIndex,
+			NominalSetpoint: res.NominalSetpoint,
+		}
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal leaf manifest: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create .npz file: %w", err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+
+	entries := []struct {
+		name string
+		data []byte
+	}{
+		{"mean.npy", npyBytes(mean)},
+		{"stddev.npy", npyBytes(stddev)},
+		{"deviation.npy", npyBytes(deviation)},
+		{"range.npy", npyBytes(positionalRange)},
+		{"is_out_of_tolerance.npy", npyBytes(isOutOfTolerance)},
+		{"leaves.json", manifestBytes},
+	}
+	for _, entry := range entries {
+		w, err := zw.Create(entry.name)
+		if err != nil {
+			return fmt.Errorf("failed to add %s to .npz: %w", entry.name, err)
+		}
+		if _, err := w.Write(entry.data); err != nil {
+			return fmt.Errorf("failed to write %s to .npz: %w", entry.name, err)
+		}
+	}
+
+	return zw.Close()
+}
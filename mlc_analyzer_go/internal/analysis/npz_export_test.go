@@ -0,0 +1,129 @@
+package analysis
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+// decodeNpyBytes is a minimal reader for the .npy bytes npyBytes produces
+// (little-endian float64, version 1.0, 1-D), used to round-trip them without
+// depending on NumPy itself.
+func decodeNpyBytes(t *testing.T, raw []byte) []float64 {
+	t.Helper()
+	if !bytes.HasPrefix(raw, []byte("\x93NUMPY\x01\x00")) {
+		t.Fatalf("missing .npy magic/version prefix")
+	}
+	headerLen := int(binary.LittleEndian.Uint16(raw[8:10]))
+	const preambleLen = 10
+	if (preambleLen+headerLen)%64 != 0 {
+		t.Fatalf("header is not padded to a 64-byte boundary: total %d", preambleLen+headerLen)
+	}
+	dict := string(raw[preambleLen : preambleLen+headerLen])
+	if !strings.Contains(dict, "'descr': '<f8'") {
+		t.Fatalf("dict missing expected descr: %q", dict)
+	}
+
+	body := raw[preambleLen+headerLen:]
+	data := make([]float64, len(body)/8)
+	if err := binary.Read(bytes.NewReader(body), binary.LittleEndian, data); err != nil {
+		t.Fatalf("failed to read float64 payload: %v", err)
+	}
+	return data
+}
+
+func TestNpyBytesRoundTrip(t *testing.T) {
+	want := []float64{1.5, -2.25, math.NaN(), 0}
+	got := decodeNpyBytes(t, npyBytes(want))
+	if len(got) != len(want) {
+		t.Fatalf("got %d values, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		if math.IsNaN(w) {
+			if !math.IsNaN(got[i]) {
+				t.Errorf("value[%d] = %v, want NaN", i, got[i])
+			}
+			continue
+		}
+		if got[i] != w {
+			t.Errorf("value[%d] = %v, want %v", i, got[i], w)
+		}
+	}
+}
+
+func TestWriteNPZRoundTrip(t *testing.T) {
+	results := NewAnalysisResults()
+	results.Results = []LeafAnalysisResult{
+		{
+			BankName: "Left MLC Bank +20", LeafIndex: 0, LeafID: "L1", NominalSetpoint: 20,
+			MeanPosition: 20.1, StdDev: 0.2, Deviation: 0.1, PositionalRange: 0.4, IsOutOfTolerance: false,
+		},
+		{
+			BankName: "Left MLC Bank +20", LeafIndex: 1, LeafID: "L2", NominalSetpoint: 20,
+			MeanPosition: 21.5, StdDev: 0.3, Deviation: 1.5, PositionalRange: 0.6, IsOutOfTolerance: true,
+		},
+	}
+
+	path := t.TempDir() + "/out.npz"
+	if err := results.WriteNPZ(path); err != nil {
+		t.Fatalf("WriteNPZ failed: %v", err)
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("failed to open written .npz as a zip: %v", err)
+	}
+	defer zr.Close()
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	readArray := func(name string) []float64 {
+		f, ok := files[name]
+		if !ok {
+			t.Fatalf("zip missing entry %q", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %q: %v", name, err)
+		}
+		defer rc.Close()
+		buf := new(bytes.Buffer)
+		if _, err := buf.ReadFrom(rc); err != nil {
+			t.Fatalf("failed to read %q: %v", name, err)
+		}
+		return decodeNpyBytes(t, buf.Bytes())
+	}
+
+	mean := readArray("mean.npy")
+	if mean[0] != 20.1 || mean[1] != 21.5 {
+		t.Errorf("mean.npy = %v, want [20.1 21.5]", mean)
+	}
+	isOutOfTolerance := readArray("is_out_of_tolerance.npy")
+	if isOutOfTolerance[0] != 0 || isOutOfTolerance[1] != 1 {
+		t.Errorf("is_out_of_tolerance.npy = %v, want [0 1]", isOutOfTolerance)
+	}
+
+	manifestFile, ok := files["leaves.json"]
+	if !ok {
+		t.Fatalf("zip missing leaves.json")
+	}
+	rc, err := manifestFile.Open()
+	if err != nil {
+		t.Fatalf("failed to open leaves.json: %v", err)
+	}
+	defer rc.Close()
+	var manifest []LeafManifestEntry
+	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+		t.Fatalf("failed to decode leaves.json: %v", err)
+	}
+	if len(manifest) != 2 || manifest[1].LeafID != "L2" || manifest[1].NominalSetpoint != 20 {
+		t.Errorf("leaves.json manifest = %+v, want entry[1].LeafID=L2, NominalSetpoint=20", manifest)
+	}
+}
@@ -0,0 +1,238 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/user/mlc_analyzer_go/internal/parser"
+)
+
+// maxJacobiSweeps bounds the cyclic Jacobi eigendecomposition below; in
+// practice off-diagonal convergence at NumLeaves-scale matrices happens in a
+// handful of sweeps, so this is a safety cap rather than a tuning knob.
+const maxJacobiSweeps = 100
+
+// PCAResult holds the output of AnalyzePCA: the requested number of leading
+// principal components of leaf-position variation across runs.
+type PCAResult struct {
+	NumComponents int
+	// LeafLabels names each row of Loadings, e.g. "Left MLC Bank +20 L1", in
+	// the same order across every component.
+	LeafLabels []string
+	// ExplainedVariance holds each component's eigenvalue.
+	ExplainedVariance []float64
+	// ExplainedVarianceRatio holds each component's share of total variance.
+	ExplainedVarianceRatio []float64
+	// Loadings[k][i] is component k's weight on LeafLabels[i].
+	Loadings [][]float64
+	// Scores[k][r] is component k's projected value for run r.
+	Scores [][]float64
+}
+
+// centeredRow imputes NaNs in values with the row's own mean (over its valid
+// entries) and then mean-centers the row, so imputed entries become exactly
+// zero.
+func centeredRow(values []float64) []float64 {
+	var sum float64
+	var count int
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			sum += v
+			count++
+		}
+	}
+	mean := 0.0
+	if count > 0 {
+		mean = sum / float64(count)
+	}
+	row := make([]float64, len(values))
+	for i, v := range values {
+		if math.IsNaN(v) {
+			row[i] = 0
+		} else {
+			row[i] = v - mean
+		}
+	}
+	return row
+}
+
+// AnalyzePCA treats every (bank, leaf) as a row sampled across NumRuns
+// columns, mean-centers each row (after NaN-imputation by its own mean), and
+// extracts the top `components` principal components of the resulting
+// leaf-by-leaf covariance matrix. This separates run-to-run variation that is
+// shared across many leaves (global machine drift) from variation that is
+// specific to individual leaves (per-leaf noise).
+func AnalyzePCA(parsed *parser.ParsedMLCData, components int) (*PCAResult, error) {
+	if parsed == nil || len(parsed.Data) == 0 {
+		return nil, fmt.Errorf("parsed data is nil or empty, cannot run PCA")
+	}
+	if parsed.NumRuns < 2 {
+		return nil, fmt.Errorf("PCA requires at least 2 runs, got %d", parsed.NumRuns)
+	}
+	if components <= 0 {
+		return nil, fmt.Errorf("components must be positive, got %d", components)
+	}
+
+	var leafLabels []string
+	var rows [][]float64
+	for _, bankName := range parsed.BankNames {
+		bankData, ok := parsed.Data[bankName]
+		if !ok {
+			continue
+		}
+		for leafIdx, leafRuns := range bankData {
+			rows = append(rows, centeredRow(leafRuns))
+			leafLabels = append(leafLabels, fmt.Sprintf("%s L%d", bankName, leafIdx+1))
+		}
+	}
+	n := len(rows)
+	if n == 0 {
+		return nil, fmt.Errorf("no leaf rows available for PCA")
+	}
+	if components > n {
+		components = n
+	}
+
+	numRuns := parsed.NumRuns
+	cov := make([][]float64, n)
+	for i := range cov {
+		cov[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			var sum float64
+			for r := 0; r < numRuns; r++ {
+				sum += rows[i][r] * rows[j][r]
+			}
+			v := sum / float64(numRuns-1)
+			cov[i][j] = v
+			cov[j][i] = v
+		}
+	}
+
+	eigenvalues, eigenvectors := jacobiEigen(cov, maxJacobiSweeps)
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return eigenvalues[order[a]] > eigenvalues[order[b]] })
+
+	var totalVariance float64
+	for _, v := range eigenvalues {
+		totalVariance += v
+	}
+
+	result := &PCAResult{
+		NumComponents:          components,
+		LeafLabels:             leafLabels,
+		ExplainedVariance:      make([]float64, components),
+		ExplainedVarianceRatio: make([]float64, components),
+		Loadings:               make([][]float64, components),
+		Scores:                 make([][]float64, components),
+	}
+
+	for k := 0; k < components; k++ {
+		idx := order[k]
+		eigenvalue := eigenvalues[idx]
+		result.ExplainedVariance[k] = eigenvalue
+		if totalVariance > 0 {
+			result.ExplainedVarianceRatio[k] = eigenvalue / totalVariance
+		}
+
+		loading := make([]float64, n)
+		for i := 0; i < n; i++ {
+			loading[i] = eigenvectors[i][idx]
+		}
+		result.Loadings[k] = loading
+
+		score := make([]float64, numRuns)
+		for r := 0; r < numRuns; r++ {
+			var s float64
+			for i := 0; i < n; i++ {
+				s += loading[i] * rows[i][r]
+			}
+			score[r] = s
+		}
+		result.Scores[k] = score
+	}
+
+	return result, nil
+}
+
+// jacobiEigen computes the eigenvalues and eigenvectors of a symmetric
+// matrix a via the cyclic Jacobi rotation method. It returns eigenvalues
+// (unordered) and eigenvectors as columns of the returned matrix, i.e.
+// eigenvectors[i][k] is the i-th component of the k-th eigenvector.
+func jacobiEigen(a [][]float64, maxSweeps int) (eigenvalues []float64, eigenvectors [][]float64) {
+	n := len(a)
+
+	A := make([][]float64, n)
+	for i := range A {
+		A[i] = append([]float64(nil), a[i]...)
+	}
+
+	V := make([][]float64, n)
+	for i := range V {
+		V[i] = make([]float64, n)
+		V[i][i] = 1
+	}
+
+	for sweep := 0; sweep < maxSweeps; sweep++ {
+		var offDiagonal float64
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				offDiagonal += A[p][q] * A[p][q]
+			}
+		}
+		if offDiagonal < 1e-20 {
+			break
+		}
+
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := A[p][q]
+				if math.Abs(apq) < 1e-300 {
+					continue
+				}
+
+				theta := (A[q][q] - A[p][p]) / (2 * apq)
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				app, aqq := A[p][p], A[q][q]
+				A[p][p] = c*c*app - 2*s*c*apq + s*s*aqq
+				A[q][q] = s*s*app + 2*s*c*apq + c*c*aqq
+				A[p][q] = 0
+				A[q][p] = 0
+
+				for i := 0; i < n; i++ {
+					if i == p || i == q {
+						continue
+					}
+					aip, aiq := A[i][p], A[i][q]
+					A[i][p] = c*aip - s*aiq
+					A[p][i] = A[i][p]
+					A[i][q] = s*aip + c*aiq
+					A[q][i] = A[i][q]
+				}
+				for i := 0; i < n; i++ {
+					vip, viq := V[i][p], V[i][q]
+					V[i][p] = c*vip - s*viq
+					V[i][q] = s*vip + c*viq
+				}
+			}
+		}
+	}
+
+	eigenvalues = make([]float64, n)
+	for i := 0; i < n; i++ {
+		eigenvalues[i] = A[i][i]
+	}
+	return eigenvalues, V
+}
@@ -0,0 +1,139 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/user/mlc_analyzer_go/internal/parser"
+)
+
+func TestJacobiEigenDiagonalMatrix(t *testing.T) {
+	// A diagonal matrix's eigenvalues are its diagonal entries.
+	a := [][]float64{
+		{2, 0, 0},
+		{0, 5, 0},
+		{0, 0, 3},
+	}
+	eigenvalues, _ := jacobiEigen(a, maxJacobiSweeps)
+
+	got := append([]float64(nil), eigenvalues...)
+	sort.Float64s(got)
+	want := []float64{2, 3, 5}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Fatalf("eigenvalues = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestJacobiEigenSymmetric2x2(t *testing.T) {
+	// [[2,1],[1,2]] has eigenvalues 1 and 3 (known by hand), eigenvectors
+	// (1,-1)/sqrt2 and (1,1)/sqrt2.
+	a := [][]float64{
+		{2, 1},
+		{1, 2},
+	}
+	eigenvalues, eigenvectors := jacobiEigen(a, maxJacobiSweeps)
+
+	gotVals := append([]float64(nil), eigenvalues...)
+	sort.Float64s(gotVals)
+	wantVals := []float64{1, 3}
+	for i := range wantVals {
+		if math.Abs(gotVals[i]-wantVals[i]) > 1e-9 {
+			t.Fatalf("eigenvalues = %v, want %v", gotVals, wantVals)
+		}
+	}
+
+	// A*v == lambda*v for every returned eigenpair, regardless of the sign
+	// or column order jacobiEigen happens to produce.
+	n := len(a)
+	for k := 0; k < n; k++ {
+		lambda := eigenvalues[k]
+		for i := 0; i < n; i++ {
+			var av float64
+			for j := 0; j < n; j++ {
+				av += a[i][j] * eigenvectors[j][k]
+			}
+			want := lambda * eigenvectors[i][k]
+			if math.Abs(av-want) > 1e-9 {
+				t.Errorf("A*v[%d] (component %d) = %v, want %v*v = %v", k, i, av, lambda, want)
+			}
+		}
+	}
+}
+
+func TestJacobiEigenPreservesTrace(t *testing.T) {
+	a := [][]float64{
+		{4, 1, 0.5},
+		{1, 3, -0.2},
+		{0.5, -0.2, 2},
+	}
+	var trace float64
+	for i := range a {
+		trace += a[i][i]
+	}
+
+	eigenvalues, _ := jacobiEigen(a, maxJacobiSweeps)
+	var sum float64
+	for _, v := range eigenvalues {
+		sum += v
+	}
+	if math.Abs(sum-trace) > 1e-9 {
+		t.Errorf("sum of eigenvalues = %v, want trace %v", sum, trace)
+	}
+}
+
+func TestAnalyzePCARejectsTooFewRuns(t *testing.T) {
+	parsed := parser.NewParsedMLCData()
+	parsed.BankNames = []string{"Left MLC Bank +20"}
+	parsed.NumRuns = 1
+	bankData := make([][]float64, parser.NumLeaves)
+	for i := range bankData {
+		bankData[i] = []float64{0}
+	}
+	parsed.Data["Left MLC Bank +20"] = bankData
+
+	if _, err := AnalyzePCA(parsed, 1); err == nil {
+		t.Error("AnalyzePCA with NumRuns=1 returned no error, want one (PCA requires >= 2 runs)")
+	}
+}
+
+func TestAnalyzePCAFindsDominantDriftComponent(t *testing.T) {
+	// Two leaves that move in lockstep (a shared "global drift") plus one
+	// independent leaf: the first principal component should explain most
+	// of the variance and load the two lockstep leaves with the same sign.
+	parsed := parser.NewParsedMLCData()
+	parsed.BankNames = []string{"Left MLC Bank +20"}
+	parsed.NumRuns = 4
+	drift := []float64{0, 1, -1, 2}
+	independent := []float64{0, -2, 1, 0}
+
+	bankData := make([][]float64, parser.NumLeaves)
+	bankData[0] = append([]float64(nil), drift...)
+	bankData[1] = append([]float64(nil), drift...)
+	bankData[2] = append([]float64(nil), independent...)
+	for i := 3; i < parser.NumLeaves; i++ {
+		bankData[i] = []float64{0, 0, 0, 0}
+	}
+	parsed.Data["Left MLC Bank +20"] = bankData
+
+	result, err := AnalyzePCA(parsed, 2)
+	if err != nil {
+		t.Fatalf("AnalyzePCA failed: %v", err)
+	}
+	if result.NumComponents != 2 || len(result.ExplainedVariance) != 2 {
+		t.Fatalf("got %d components, want 2", result.NumComponents)
+	}
+	if result.ExplainedVarianceRatio[0] <= result.ExplainedVarianceRatio[1] {
+		t.Errorf("expected the first component to explain more variance than the second: %v", result.ExplainedVarianceRatio)
+	}
+
+	// Leaves 0 and 1 are identical rows, so they must load identically
+	// (same sign and magnitude) on every component.
+	for k := 0; k < result.NumComponents; k++ {
+		if math.Abs(result.Loadings[k][0]-result.Loadings[k][1]) > 1e-9 {
+			t.Errorf("component %d: identical leaves 0 and 1 have different loadings %v vs %v", k, result.Loadings[k][0], result.Loadings[k][1])
+		}
+	}
+}
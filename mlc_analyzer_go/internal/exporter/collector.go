@@ -0,0 +1,117 @@
+// Package exporter exposes MLC QA analysis results as Prometheus metrics so
+// they can be scraped by a monitoring stack rather than only read off a
+// generated PDF.
+package exporter
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/user/mlc_analyzer_go/internal/analysis"
+)
+
+// Collector holds the Prometheus metrics for the latest AnalyzeMLCData run
+// and serves them on its own registry via Handler.
+type Collector struct {
+	registry *prometheus.Registry
+
+	deviation       *prometheus.GaugeVec
+	stdDev          *prometheus.GaugeVec
+	positionalRange *prometheus.GaugeVec
+	outOfTolerance  *prometheus.GaugeVec
+	position        *prometheus.HistogramVec
+}
+
+// NewCollector builds and registers the MLC QA metric set on its own
+// prometheus.Registry (rather than the global default), so StartMetricsServer
+// can be called again for a later run without a "duplicate metrics"
+// registration panic.
+func NewCollector() *Collector {
+	c := &Collector{registry: prometheus.NewRegistry()}
+
+	c.deviation = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mlc_leaf_deviation_mm",
+		Help: "Mean leaf position deviation from its nominal setpoint, in mm.",
+	}, []string{"bank", "leaf", "nominal"})
+
+	c.stdDev = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mlc_leaf_stddev_mm",
+		Help: "Standard deviation of leaf position across runs, in mm.",
+	}, []string{"bank", "leaf"})
+
+	c.positionalRange = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mlc_leaf_range_mm",
+		Help: "Max-min range of leaf position across runs, in mm.",
+	}, []string{"bank", "leaf"})
+
+	c.outOfTolerance = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mlc_leaf_out_of_tolerance",
+		Help: "1 if the leaf's deviation exceeds the configured tolerance, else 0.",
+	}, []string{"bank", "leaf"})
+
+	c.position = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:                            "mlc_leaf_position_mm",
+		Help:                            "Distribution of measured leaf positions across leaves and runs, in mm.",
+		NativeHistogramBucketFactor:     1.1,
+		NativeHistogramMaxBucketNumber:  160,
+		NativeHistogramMinResetDuration: time.Hour,
+	}, []string{"bank"})
+
+	c.registry.MustRegister(c.deviation, c.stdDev, c.positionalRange, c.outOfTolerance, c.position)
+	return c
+}
+
+// Update replaces the gauges with results's values and observes every valid
+// measurement into the per-bank position histogram. Leaves with zero valid
+// runs are skipped entirely rather than emitted as a misleading zero.
+//
+// Labels are bounded by construction: at most len(parser.TargetBankRows)
+// banks times parser.NumLeaves leaves.
+func (c *Collector) Update(results *analysis.AnalysisResults) {
+	if results == nil {
+		return
+	}
+	c.deviation.Reset()
+	c.stdDev.Reset()
+	c.positionalRange.Reset()
+	c.outOfTolerance.Reset()
+	c.position.Reset()
+	for _, res := range results.Results {
+		if res.NumValidRuns == 0 {
+			continue
+		}
+		leafLabel := fmt.Sprintf("%d", res.LeafIndex+1)
+		nominalLabel := fmt.Sprintf("%d", res.NominalSetpoint)
+
+		if !math.IsNaN(res.Deviation) {
+			c.deviation.WithLabelValues(res.BankName, leafLabel, nominalLabel).Set(res.Deviation)
+		}
+		if !math.IsNaN(res.StdDev) {
+			c.stdDev.WithLabelValues(res.BankName, leafLabel).Set(res.StdDev)
+		}
+		if !math.IsNaN(res.PositionalRange) {
+			c.positionalRange.WithLabelValues(res.BankName, leafLabel).Set(res.PositionalRange)
+		}
+
+		outOfTolerance := 0.0
+		if res.IsOutOfTolerance {
+			outOfTolerance = 1.0
+		}
+		c.outOfTolerance.WithLabelValues(res.BankName, leafLabel).Set(outOfTolerance)
+
+		for _, m := range res.Measurements {
+			c.position.WithLabelValues(res.BankName).Observe(m)
+		}
+	}
+}
+
+// Handler returns the HTTP handler serving this collector's registry in the
+// Prometheus exposition format, for mounting at e.g. "/metrics".
+func (c *Collector) Handler() http.Handler {
+	return promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{})
+}
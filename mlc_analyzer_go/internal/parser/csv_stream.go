@@ -0,0 +1,264 @@
+package parser
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ParseOptions configures ParseMLCDataStream.
+type ParseOptions struct {
+	// Workers is the number of goroutines parsing bank rows concurrently.
+	// Zero (the default) uses runtime.NumCPU().
+	Workers int
+}
+
+// bankRowJob is one target-bank CSV row queued for a parse worker, tagged
+// with the run it belongs to so the aggregator can place it correctly even
+// though workers may finish out of submission order.
+type bankRowJob struct {
+	runIndex  int
+	bankName  string
+	rowIdx    int
+	rawValues []string
+}
+
+// bankRowResult is a bankRowJob after a worker has parsed it into a fixed
+// NumLeaves-wide, NaN-padded slice.
+type bankRowResult struct {
+	runIndex      int
+	bankName      string
+	leafPositions []float64
+	parseErrors   []string
+	// warningOnly marks a result carrying nothing but parseErrors (e.g. a
+	// warning raised by the reading goroutine itself) so the aggregator
+	// appends them without treating the zero-value bankName/leafPositions
+	// as a real row.
+	warningOnly bool
+}
+
+// ParseMLCDataStream parses MLC leaf position data from r without loading
+// the whole file into memory at once: a single goroutine reads CSV records
+// and detects run boundaries ("Name,Value" headers) and target-bank rows,
+// fanning the row-parsing work (string -> []float64 conversion) out across
+// opts.Workers goroutines. Only the aggregation step -- placing each parsed
+// row into parsedData.Data -- touches shared state, and it runs on a single
+// goroutine, so no locking is needed anywhere in the pipeline. Per-bank run
+// columns grow geometrically as higher run indices arrive, since the final
+// run count isn't known until r is exhausted.
+func ParseMLCDataStream(r io.Reader, opts ParseOptions) (*ParsedMLCData, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.TrimLeadingSpace = true
+	// "Name,Value" header rows have 2 fields; target-bank rows have 1 + up to
+	// NumLeaves. Without this, csv.Reader enforces every record matching the
+	// first record's field count and every bank row errors out.
+	reader.FieldsPerRecord = -1
+
+	parsedData := NewParsedMLCData()
+
+	jobs := make(chan bankRowJob, workers*2)
+	results := make(chan bankRowResult, workers*2)
+
+	var workerWG sync.WaitGroup
+	workerWG.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer workerWG.Done()
+			for job := range jobs {
+				results <- parseBankRow(job)
+			}
+		}()
+	}
+	go func() {
+		workerWG.Wait()
+		close(results)
+	}()
+
+	bankRunCap := make(map[string]int) // per-bank current column capacity
+	numRuns := 0
+
+	aggregate := func(res bankRowResult) {
+		parsedData.ParseErrors = append(parsedData.ParseErrors, res.parseErrors...)
+		if res.warningOnly {
+			return
+		}
+
+		bankData, ok := parsedData.Data[res.bankName]
+		if !ok {
+			parsedData.BankNames = append(parsedData.BankNames, res.bankName)
+			bankData = make([][]float64, NumLeaves)
+			for leafIdx := range bankData {
+				bankData[leafIdx] = make([]float64, 0, 64)
+			}
+			parsedData.Data[res.bankName] = bankData
+		}
+
+		requiredLen := res.runIndex + 1
+		if requiredLen > bankRunCap[res.bankName] {
+			newCap := bankRunCap[res.bankName]
+			if newCap == 0 {
+				newCap = 64
+			}
+			for newCap < requiredLen {
+				newCap *= 2
+			}
+			for leafIdx := range bankData {
+				grown := make([]float64, newCap)
+				copy(grown, bankData[leafIdx])
+				for i := len(bankData[leafIdx]); i < newCap; i++ {
+					grown[i] = math.NaN()
+				}
+				bankData[leafIdx] = grown
+			}
+			bankRunCap[res.bankName] = newCap
+		}
+
+		for leafIdx := 0; leafIdx < NumLeaves; leafIdx++ {
+			bankData[leafIdx][res.runIndex] = res.leafPositions[leafIdx]
+		}
+
+		if requiredLen > numRuns {
+			numRuns = requiredLen
+		}
+	}
+
+	// Drain results concurrently with record reading below, so a full
+	// results channel (a slow aggregate step) can't block workers from
+	// finishing, which would otherwise deadlock the job submission loop.
+	aggDone := make(chan struct{})
+	go func() {
+		for res := range results {
+			aggregate(res)
+		}
+		close(aggDone)
+	}()
+
+	currentRunIndex := -1
+	rowIdx := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			close(jobs)
+			<-aggDone
+			return nil, fmt.Errorf("failed to read CSV data: %w", err)
+		}
+		rowIdx++
+
+		if len(row) == 0 || row[0] == "" {
+			continue
+		}
+
+		if row[0] == "Name" && len(row) > 1 && row[1] == "Value" {
+			currentRunIndex++
+			continue
+		}
+
+		if isTargetBankRow(row[0]) {
+			if currentRunIndex < 0 {
+				// Only the aggregator goroutine may touch parsedData, so route
+				// this warning through the results channel instead of
+				// appending to parsedData.ParseErrors directly here.
+				results <- bankRowResult{
+					warningOnly: true,
+					parseErrors: []string{fmt.Sprintf("Warning: Data for bank '%s' (CSV row %d) found before a 'Name,Value' header. Assigning to run 0.", row[0], rowIdx)},
+				}
+				currentRunIndex = 0
+			}
+			// Copy row[1:] since the csv.Reader may reuse its backing array
+			// on the next Read call.
+			jobs <- bankRowJob{
+				runIndex:  currentRunIndex,
+				bankName:  row[0],
+				rowIdx:    rowIdx,
+				rawValues: append([]string(nil), row[1:]...),
+			}
+		}
+	}
+	close(jobs)
+	<-aggDone
+
+	finalNumRuns := numRuns
+	if currentRunIndex+1 > finalNumRuns {
+		finalNumRuns = currentRunIndex + 1
+	}
+	parsedData.NumRuns = finalNumRuns
+
+	for _, bankData := range parsedData.Data {
+		for leafIdx := range bankData {
+			leafRuns := bankData[leafIdx]
+			if len(leafRuns) == finalNumRuns {
+				continue
+			}
+			grown := make([]float64, finalNumRuns)
+			copy(grown, leafRuns)
+			for i := len(leafRuns); i < finalNumRuns; i++ {
+				grown[i] = math.NaN()
+			}
+			bankData[leafIdx] = grown
+		}
+	}
+
+	if parsedData.NumRuns == 0 {
+		parsedData.ParseErrors = append(parsedData.ParseErrors, "Warning: No data blocks parsed or no runs found.")
+	}
+
+	return parsedData, nil
+}
+
+// parseBankRow converts a bankRowJob's raw CSV string cells into a fixed
+// NumLeaves-wide, NaN-padded float64 slice. It is pure and touches no shared
+// state, so it's safe to run concurrently across worker goroutines.
+func parseBankRow(job bankRowJob) bankRowResult {
+	var valuesStrList []string
+	for _, item := range job.rawValues {
+		trimmedItem := strings.TrimSpace(item)
+		if strings.ToLower(trimmedItem) == "mm" { // Stop before "mm" unit
+			break
+		}
+		if trimmedItem != "" {
+			valuesStrList = append(valuesStrList, trimmedItem)
+		}
+	}
+
+	leafPositions := make([]float64, NumLeaves)
+	for i := range leafPositions {
+		leafPositions[i] = math.NaN()
+	}
+
+	var parseErrors []string
+	if len(valuesStrList) == 0 {
+		parseErrors = append(parseErrors, fmt.Sprintf("Warning: Run %d, Bank '%s' (CSV row %d) - No numeric values found. All leaves set to NaN.", job.runIndex+1, job.bankName, job.rowIdx))
+	} else {
+		for i, valStr := range valuesStrList {
+			if i >= NumLeaves {
+				parseErrors = append(parseErrors, fmt.Sprintf("Warning: Run %d, Bank '%s' - More than %d values found, truncating.", job.runIndex+1, job.bankName, NumLeaves))
+				break
+			}
+			val, err := strconv.ParseFloat(valStr, 64)
+			if err != nil {
+				parseErrors = append(parseErrors, fmt.Sprintf("Error converting value '%s' for Bank '%s', Run %d, Leaf approx %d. Using NaN. Error: %v", valStr, job.bankName, job.runIndex+1, i+1, err))
+			} else {
+				leafPositions[i] = val
+			}
+		}
+		if len(valuesStrList) < NumLeaves {
+			parseErrors = append(parseErrors, fmt.Sprintf("Warning: Run %d, Bank '%s' - Expected %d values, found %d. Remaining leaves set to NaN.", job.runIndex+1, job.bankName, NumLeaves, len(valuesStrList)))
+		}
+	}
+
+	return bankRowResult{runIndex: job.runIndex, bankName: job.bankName, leafPositions: leafPositions, parseErrors: parseErrors}
+}
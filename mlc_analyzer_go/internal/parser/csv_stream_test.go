@@ -0,0 +1,98 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildFixtureCSV writes numRuns "Name,Value" blocks, each followed by one
+// row per bank in bankNames with NumLeaves values laid out as
+// runIndex*1000 + leafIndex, so the expected parsed value for any
+// (bank, leaf, run) is trivially reconstructible.
+func buildFixtureCSV(bankNames []string, numRuns int) string {
+	var b strings.Builder
+	for run := 0; run < numRuns; run++ {
+		b.WriteString("Name,Value\n")
+		for _, bank := range bankNames {
+			b.WriteString(bank)
+			for leaf := 0; leaf < NumLeaves; leaf++ {
+				fmt.Fprintf(&b, ",%d", run*1000+leaf)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func TestParseMLCDataStreamMatchesExpectedLayout(t *testing.T) {
+	bankNames := []string{"Left MLC Bank +20", "Right MLC Bank -60"}
+	const numRuns = 3
+	csvContent := buildFixtureCSV(bankNames, numRuns)
+
+	for _, workers := range []int{1, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			parsed, err := ParseMLCDataStream(strings.NewReader(csvContent), ParseOptions{Workers: workers})
+			if err != nil {
+				t.Fatalf("ParseMLCDataStream failed: %v", err)
+			}
+			if parsed.NumRuns != numRuns {
+				t.Fatalf("NumRuns = %d, want %d", parsed.NumRuns, numRuns)
+			}
+			if len(parsed.BankNames) != len(bankNames) {
+				t.Fatalf("BankNames = %v, want %v", parsed.BankNames, bankNames)
+			}
+			for _, bank := range bankNames {
+				bankData, ok := parsed.Data[bank]
+				if !ok {
+					t.Fatalf("missing bank %q in parsed data", bank)
+				}
+				if len(bankData) != NumLeaves {
+					t.Fatalf("bank %q has %d leaves, want %d", bank, len(bankData), NumLeaves)
+				}
+				for leaf := 0; leaf < NumLeaves; leaf++ {
+					if len(bankData[leaf]) != numRuns {
+						t.Fatalf("bank %q leaf %d has %d runs, want %d", bank, leaf, len(bankData[leaf]), numRuns)
+					}
+					for run := 0; run < numRuns; run++ {
+						want := float64(run*1000 + leaf)
+						if got := bankData[leaf][run]; got != want {
+							t.Errorf("bank %q leaf %d run %d = %v, want %v", bank, leaf, run, got, want)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseMLCDataStreamDataBeforeHeaderIsAssignedToRunZero(t *testing.T) {
+	bank := "Left MLC Bank +20"
+	var b strings.Builder
+	b.WriteString(bank)
+	for leaf := 0; leaf < NumLeaves; leaf++ {
+		fmt.Fprintf(&b, ",%d", leaf)
+	}
+	b.WriteString("\n")
+
+	parsed, err := ParseMLCDataStream(strings.NewReader(b.String()), ParseOptions{Workers: 4})
+	if err != nil {
+		t.Fatalf("ParseMLCDataStream failed: %v", err)
+	}
+	if parsed.NumRuns != 1 {
+		t.Fatalf("NumRuns = %d, want 1", parsed.NumRuns)
+	}
+	if got := parsed.Data[bank][0][0]; got != 0 {
+		t.Errorf("leaf 0 run 0 = %v, want 0", got)
+	}
+
+	foundWarning := false
+	for _, msg := range parsed.ParseErrors {
+		if strings.Contains(msg, "found before a 'Name,Value' header") {
+			foundWarning = true
+		}
+	}
+	if !foundWarning {
+		t.Errorf("ParseErrors = %v, want a warning about data before the header", parsed.ParseErrors)
+	}
+}
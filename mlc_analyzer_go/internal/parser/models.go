@@ -1,7 +1,5 @@
 package parser
 
-import "fmt"
-
 const NumLeaves = 80
 
 // BankLeafData holds all measurements for a single leaf in a specific bank across all runs.
@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// BankManifestEntry describes one bank's position and nominal setpoint within
+// the 3-D array written by WriteNPY, so a Python reader can line the raw
+// float64 buffer back up with bank names without re-parsing the CSV.
+type BankManifestEntry struct {
+	BankName        string `json:"bank_name"`
+	NominalSetpoint int    `json:"nominal_setpoint"`
+}
+
+// npyHeader builds the ASCII header for a little-endian float64 array of the
+// given shape, padded so the whole header (magic + version + header length +
+// dict) ends on a 64-byte boundary, as required by the .npy format spec.
+func npyHeader(shape []int) []byte {
+	shapeStrs := make([]string, len(shape))
+	for i, s := range shape {
+		shapeStrs[i] = fmt.Sprintf("%d", s)
+	}
+	shapeTuple := ""
+	for i, s := range shapeStrs {
+		if i > 0 {
+			shapeTuple += ", "
+		}
+		shapeTuple += s
+	}
+	if len(shapeStrs) == 1 {
+		shapeTuple += ","
+	}
+
+	dict := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%s), }", shapeTuple)
+
+	// magic(6) + version(2) + header-length field(2) = 10 bytes of preamble;
+	// pad the dict with spaces (plus trailing newline) to a 64-byte multiple.
+	const preambleLen = 10
+	padded := preambleLen + len(dict) + 1
+	padLen := (64 - padded%64) % 64
+	for i := 0; i < padLen; i++ {
+		dict += " "
+	}
+	dict += "\n"
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("\x93NUMPY")
+	buf.WriteByte(1) // major version
+	buf.WriteByte(0) // minor version
+	binary.Write(buf, binary.LittleEndian, uint16(len(dict)))
+	buf.WriteString(dict)
+	return buf.Bytes()
+}
+
+// writeNPYArray writes data (already flattened in C order) as a .npy file of
+// the given shape to w.
+func writeNPYArray(w *os.File, shape []int, data []float64) error {
+	if _, err := w.Write(npyHeader(shape)); err != nil {
+		return fmt.Errorf("failed to write .npy header: %w", err)
+	}
+	if err := binary.Write(w, binary.LittleEndian, data); err != nil {
+		return fmt.Errorf("failed to write .npy data: %w", err)
+	}
+	return nil
+}
+
+// WriteNPY serializes Data as a single 3-D NumPy array shaped
+// [num_banks, NumLeaves, NumRuns] (C order, float64, NaNs preserved) to path,
+// alongside a "<path>.banks.json" manifest recording bank order and nominal
+// setpoints so the array can be interpreted without the original CSV.
+func (p *ParsedMLCData) WriteNPY(path string) error {
+	if len(p.BankNames) == 0 {
+		return fmt.Errorf("no parsed bank data to export")
+	}
+
+	data := make([]float64, 0, len(p.BankNames)*NumLeaves*p.NumRuns)
+	manifest := make([]BankManifestEntry, 0, len(p.BankNames))
+	for _, bankName := range p.BankNames {
+		nominal, err := ExtractNominalFromBankName(bankName)
+		if err != nil {
+			nominal = 0
+		}
+		manifest = append(manifest, BankManifestEntry{BankName: bankName, NominalSetpoint: nominal})
+
+		bankData := p.Data[bankName]
+		for leafIdx := 0; leafIdx < NumLeaves; leafIdx++ {
+			data = append(data, bankData[leafIdx]...)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create .npy file: %w", err)
+	}
+	defer file.Close()
+
+	shape := []int{len(p.BankNames), NumLeaves, p.NumRuns}
+	if err := writeNPYArray(file, shape, data); err != nil {
+		return err
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bank manifest: %w", err)
+	}
+	if err := os.WriteFile(path+".banks.json", manifestBytes, 0644); err != nil {
+		return fmt.Errorf("failed to write bank manifest: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// decodeNPY is a minimal reader for the subset of the .npy format WriteNPY
+// produces (little-endian float64, version 1.0), used to round-trip what
+// npyHeader/writeNPYArray wrote without depending on NumPy itself.
+func decodeNPY(t *testing.T, raw []byte) (shape []int, data []float64) {
+	t.Helper()
+	if !bytes.HasPrefix(raw, []byte("\x93NUMPY")) {
+		t.Fatalf("missing .npy magic prefix")
+	}
+	major, minor := raw[6], raw[7]
+	if major != 1 || minor != 0 {
+		t.Fatalf("unexpected version %d.%d", major, minor)
+	}
+	headerLen := int(binary.LittleEndian.Uint16(raw[8:10]))
+	const preambleLen = 10
+	if preambleLen+headerLen > len(raw) {
+		t.Fatalf("header length %d overruns buffer of %d bytes", headerLen, len(raw))
+	}
+	if (preambleLen+headerLen)%64 != 0 {
+		t.Fatalf("header is not padded to a 64-byte boundary: total %d", preambleLen+headerLen)
+	}
+	dict := string(raw[preambleLen : preambleLen+headerLen])
+	if !strings.Contains(dict, "'descr': '<f8'") {
+		t.Fatalf("dict missing expected descr: %q", dict)
+	}
+
+	shapeStart := strings.Index(dict, "(")
+	shapeEnd := strings.Index(dict, ")")
+	if shapeStart < 0 || shapeEnd < 0 || shapeEnd < shapeStart {
+		t.Fatalf("dict missing shape tuple: %q", dict)
+	}
+	for _, part := range strings.Split(dict[shapeStart+1:shapeEnd], ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			t.Fatalf("failed to parse shape component %q: %v", part, err)
+		}
+		shape = append(shape, n)
+	}
+
+	body := raw[preambleLen+headerLen:]
+	data = make([]float64, len(body)/8)
+	if err := binary.Read(bytes.NewReader(body), binary.LittleEndian, data); err != nil {
+		t.Fatalf("failed to read float64 payload: %v", err)
+	}
+	return shape, data
+}
+
+func TestNpyHeaderIsPaddedTo64Bytes(t *testing.T) {
+	for _, shape := range [][]int{{1}, {10, 80, 3}, {2, 80, 1000}} {
+		header := npyHeader(shape)
+		if len(header)%64 != 0 {
+			t.Errorf("shape %v: header length %d is not a multiple of 64", shape, len(header))
+		}
+		if !bytes.HasPrefix(header, []byte("\x93NUMPY\x01\x00")) {
+			t.Errorf("shape %v: header missing magic/version prefix", shape)
+		}
+	}
+}
+
+func TestWriteNPYRoundTrip(t *testing.T) {
+	p := NewParsedMLCData()
+	p.BankNames = []string{"Left MLC Bank +20"}
+	p.NumRuns = 2
+	bankData := make([][]float64, NumLeaves)
+	for i := range bankData {
+		bankData[i] = []float64{float64(i), math.NaN()}
+	}
+	p.Data["Left MLC Bank +20"] = bankData
+
+	path := t.TempDir() + "/out.npy"
+	if err := p.WriteNPY(path); err != nil {
+		t.Fatalf("WriteNPY failed: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read written .npy file: %v", err)
+	}
+	shape, data := decodeNPY(t, raw)
+
+	wantShape := []int{1, NumLeaves, 2}
+	if len(shape) != len(wantShape) {
+		t.Fatalf("shape = %v, want %v", shape, wantShape)
+	}
+	for i, s := range shape {
+		if s != wantShape[i] {
+			t.Errorf("shape[%d] = %d, want %d", i, s, wantShape[i])
+		}
+	}
+
+	if len(data) != NumLeaves*2 {
+		t.Fatalf("got %d float64 values, want %d", len(data), NumLeaves*2)
+	}
+	for leafIdx := 0; leafIdx < NumLeaves; leafIdx++ {
+		if data[leafIdx*2] != float64(leafIdx) {
+			t.Errorf("leaf %d run 0 = %v, want %v", leafIdx, data[leafIdx*2], float64(leafIdx))
+		}
+		if !math.IsNaN(data[leafIdx*2+1]) {
+			t.Errorf("leaf %d run 1 = %v, want NaN", leafIdx, data[leafIdx*2+1])
+		}
+	}
+
+	manifestBytes, err := os.ReadFile(path + ".banks.json")
+	if err != nil {
+		t.Fatalf("failed to read bank manifest: %v", err)
+	}
+	if !strings.Contains(string(manifestBytes), `"bank_name": "Left MLC Bank +20"`) ||
+		!strings.Contains(string(manifestBytes), `"nominal_setpoint": 20`) {
+		t.Errorf("manifest missing expected bank entry: %s", manifestBytes)
+	}
+}
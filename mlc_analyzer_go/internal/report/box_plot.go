@@ -0,0 +1,119 @@
+package report
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/user/mlc_analyzer_go/internal/analysis"
+	"github.com/user/mlc_analyzer_go/internal/parser" // For NumLeaves
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// CreateBoxPlot renders a per-leaf Tukey box plot (median, quartiles,
+// whiskers, 1.5*IQR outliers) for every bank whose name contains bankFilter
+// (case-insensitive), built from each run's deviation from its own bank's
+// nominal setpoint (LeafAnalysisResult.Measurements minus NominalSetpoint).
+// A side (e.g. "Left") spans 5 physical banks at different nominal
+// setpoints, so boxing deviation rather than raw position lets every
+// matching bank's runs for a leaf combine into one meaningful
+// reproducibility distribution instead of one overwriting another. This
+// gives QA staff the full distributional view of reproducibility that
+// CreateLinePlot's mean+/-std-dev summary collapses away.
+func CreateBoxPlot(analysisResults *analysis.AnalysisResults, bankFilter string, toleranceMM float64, format PlotFormat) ([]byte, error) {
+	p, err := buildBoxPlot(analysisResults, bankFilter, toleranceMM)
+	if err != nil {
+		return nil, err
+	}
+	return renderPlot(p, DefaultLinePlotSize, format)
+}
+
+func buildBoxPlot(analysisResults *analysis.AnalysisResults, bankFilter string, toleranceMM float64) (*plot.Plot, error) {
+	if analysisResults == nil || len(analysisResults.Results) == 0 {
+		return nil, fmt.Errorf("no analysis results to plot")
+	}
+
+	leafDeviations := make(map[int][]float64, parser.NumLeaves)
+	for _, res := range analysisResults.Results {
+		if bankFilter != "" && !strings.Contains(strings.ToLower(res.BankName), strings.ToLower(bankFilter)) {
+			continue
+		}
+		for _, m := range res.Measurements {
+			leafDeviations[res.LeafIndex] = append(leafDeviations[res.LeafIndex], m-float64(res.NominalSetpoint))
+		}
+	}
+	if len(leafDeviations) == 0 {
+		return nil, fmt.Errorf("no results found for bank filter %q", bankFilter)
+	}
+
+	titleBankPart := "All Banks"
+	if bankFilter != "" {
+		titleBankPart = bankFilter
+	}
+	p := plot.New()
+	p.Title.Text = fmt.Sprintf("Per-Leaf Reproducibility (Box Plot) (%s)", titleBankPart)
+	p.X.Label.Text = "Leaf Number"
+	p.Y.Label.Text = "Deviation from Nominal (mm)"
+	p.X.Min = 0
+	p.X.Max = float64(parser.NumLeaves + 1)
+	p.X.Tick.Marker = plot.ConstantTicks(generateTicks(0, parser.NumLeaves, 5, true))
+	p.Add(plotter.NewGrid())
+
+	// Box width is a physical (not data-space) length; scale it to the
+	// default line-plot canvas so boxes stay readable but don't overlap
+	// their neighbors across 80 leaves.
+	boxWidth := vg.Length(DefaultLinePlotSize.WidthMM/float64(parser.NumLeaves)*0.6) * vg.Millimeter
+
+	var outliers, toleranceOutliers plotter.XYs
+	for leafIdx := 0; leafIdx < parser.NumLeaves; leafIdx++ {
+		deviations, ok := leafDeviations[leafIdx]
+		if !ok || len(deviations) == 0 {
+			continue
+		}
+		x := float64(leafIdx + 1)
+		box, err := plotter.NewBoxPlot(boxWidth, x, plotter.Values(deviations))
+		if err != nil {
+			return nil, fmt.Errorf("failed to build box plot for leaf %d: %v", leafIdx+1, err)
+		}
+
+		// NewBoxPlot already flags points outside 1.5*IQR in box.Outside.
+		// Pull those out and draw them ourselves so the ones beyond
+		// toleranceMM can be colored red instead of all outliers sharing
+		// the box's default glyph color.
+		outsideIdx := box.Outside
+		box.Outside = nil
+		for _, sampleIdx := range outsideIdx {
+			v := box.Values.Value(sampleIdx)
+			pt := plotter.XY{X: x, Y: v}
+			if toleranceMM > 0 && math.Abs(v) > toleranceMM {
+				toleranceOutliers = append(toleranceOutliers, pt)
+			} else {
+				outliers = append(outliers, pt)
+			}
+		}
+		p.Add(box)
+	}
+
+	if len(outliers) > 0 {
+		sc, err := plotter.NewScatter(outliers)
+		if err == nil {
+			sc.GlyphStyle.Color = color.Gray{Y: 80}
+			p.Add(sc)
+		}
+	}
+	if len(toleranceOutliers) > 0 {
+		sc, err := plotter.NewScatter(toleranceOutliers)
+		if err == nil {
+			sc.GlyphStyle.Color = color.RGBA{R: 255, A: 255}
+			p.Add(sc)
+			p.Legend.Add(fmt.Sprintf("Outlier beyond %.1fmm tolerance", toleranceMM), sc)
+			p.Legend.Top = true
+		}
+	}
+
+	return p, nil
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/user/mlc_analyzer_go/internal/analysis"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// CompositeReportLayout configures CreateCompositeReport's grid and output.
+type CompositeReportLayout struct {
+	ToleranceMM float64
+	Format      PlotFormat
+	Size        PlotSize // overall canvas size; defaults to A4Landscape if zero
+}
+
+// A4Landscape is the physical size (in mm) of an A4 sheet in landscape
+// orientation, used as CreateCompositeReport's default canvas.
+var A4Landscape = PlotSize{WidthMM: 297, HeightMM: 210}
+
+// CreateCompositeReport stacks the four plots QA physicists paste into
+// reports in one column on a single A4-page canvas using plot.Align on a
+// draw.Tiles grid: row 1 deviation, row 2 reproducibility, row 3 deviation
+// heatmap, row 4 std-dev heatmap. Each row keeps its own Y-axis scale and
+// draws its own leaf-number X axis; the X axes are not shared, and the line
+// rows (0 to 80) and heatmap rows (-0.5 to 79.5) use different X ranges.
+func CreateCompositeReport(analysisResults *analysis.AnalysisResults, layout CompositeReportLayout) ([]byte, error) {
+	if analysisResults == nil || len(analysisResults.Results) == 0 {
+		return nil, fmt.Errorf("no analysis results to build composite report")
+	}
+	size := layout.Size
+	if size.WidthMM == 0 || size.HeightMM == 0 {
+		size = A4Landscape
+	}
+
+	deviationPlot, err := buildLinePlot(analysisResults, "deviation", "", layout.ToleranceMM, PaletteDefault)
+	if err != nil {
+		return nil, fmt.Errorf("composite report: deviation plot: %w", err)
+	}
+	reproPlot, err := buildLinePlot(analysisResults, "reproducibility", "", layout.ToleranceMM, PaletteDefault)
+	if err != nil {
+		return nil, fmt.Errorf("composite report: reproducibility plot: %w", err)
+	}
+	deviationHeatmap, _, _, _, err := buildHeatmapPlot(analysisResults, "Deviation (mm)", "Mean Leaf Deviation (mm)", "")
+	if err != nil {
+		return nil, fmt.Errorf("composite report: deviation heatmap: %w", err)
+	}
+	stdDevHeatmap, _, _, _, err := buildHeatmapPlot(analysisResults, "Std Dev (mm)", "Leaf Reproducibility (Std Dev mm)", "")
+	if err != nil {
+		return nil, fmt.Errorf("composite report: std-dev heatmap: %w", err)
+	}
+
+	rows := [][]*plot.Plot{{deviationPlot}, {reproPlot}, {deviationHeatmap}, {stdDevHeatmap}}
+
+	c, err := newPlotCanvas(size, layout.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	tiles := draw.Tiles{
+		Rows: len(rows), Cols: 1,
+		PadY: vg.Millimeter * 4,
+		PadTop: vg.Millimeter * 2, PadBottom: vg.Millimeter * 2,
+		PadLeft: vg.Millimeter * 2, PadRight: vg.Millimeter * 2,
+	}
+
+	canvas := draw.New(c)
+	cells := plot.Align(rows, tiles, canvas)
+	for r, row := range rows {
+		for col, p := range row {
+			p.Draw(cells[r][col])
+		}
+	}
+
+	return encodePlotCanvas(c)
+}
@@ -1,10 +1,8 @@
 package report
 
 import (
-	"bytes"
 	"fmt"
 	"image/color"
-	"log"
 	"math"
 	"sort"
 	"strings"
@@ -15,19 +13,27 @@ import (
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/palette"
 	"gonum.org/v1/plot/plotter"
-	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
 )
 
-// CustomBoundaryNormColormap is a colormap that uses specific colors for defined boundaries.
+// CustomBoundaryNormColormap is a discrete, matplotlib BoundaryNorm-style
+// colormap: Boundaries holds N+1 cut points for N Colors, so a value z falls
+// in Colors[i] when Boundaries[i] <= z < Boundaries[i+1].
+// It implements palette.ColorMap, so it drives plotter.ColorBar directly and
+// plotter.HeatMap through the palette.Palette that its Palette method samples.
 type CustomBoundaryNormColormap struct {
-	Boundaries []float64        // N+1 boundaries for N colors
-	Colors     []color.Color    // N colors
-	UnderColor color.Color      // Color for values below the first boundary
-	OverColor  color.Color      // Color for values above the last boundary
-	NaNColor   color.Color      // Color for NaN values
+	Boundaries []float64     // N+1 boundaries for N colors
+	Colors     []color.Color // N colors
+	UnderColor color.Color   // Color for values below the first boundary
+	OverColor  color.Color   // Color for values at or above the last boundary
+	NaNColor   color.Color   // Color for NaN values
+
+	min, max float64
+	alpha    float64
 }
 
-// Color returns the color for a given z value.
+// Color returns the color bucket for a given z value, binary-searching
+// Boundaries rather than scanning them linearly.
 func (cm *CustomBoundaryNormColormap) Color(z float64) color.Color {
 	if math.IsNaN(z) {
 		return cm.NaNColor
@@ -35,28 +41,91 @@ func (cm *CustomBoundaryNormColormap) Color(z float64) color.Color {
 	if z < cm.Boundaries[0] {
 		return cm.UnderColor
 	}
-	for i := 0; i < len(cm.Colors); i++ {
-		if z >= cm.Boundaries[i] && z < cm.Boundaries[i+1] {
-			return cm.Colors[i]
-		}
+	// sort.Search finds the first boundary strictly greater than z; since
+	// Colors[i] covers [Boundaries[i], Boundaries[i+1]), the bucket index is
+	// one less than that. The z < Boundaries[0] guard above guarantees idx
+	// is always >= 1 here.
+	idx := sort.Search(len(cm.Boundaries), func(i int) bool { return cm.Boundaries[i] > z })
+	bucket := idx - 1
+	if bucket >= len(cm.Colors) {
+		return cm.OverColor
 	}
-	return cm.OverColor
+	return cm.Colors[bucket]
+}
+
+// At implements palette.ColorMap.
+func (cm *CustomBoundaryNormColormap) At(v float64) (color.Color, error) {
+	return cm.Color(v), nil
 }
 
-// Palette returns a palette.Palette for this colormap.
-// This is a simplified version for gonum/plot's HeatMap.
+func (cm *CustomBoundaryNormColormap) Max() float64      { return cm.max }
+func (cm *CustomBoundaryNormColormap) SetMax(v float64)  { cm.max = v }
+func (cm *CustomBoundaryNormColormap) Min() float64      { return cm.min }
+func (cm *CustomBoundaryNormColormap) SetMin(v float64)  { cm.min = v }
+func (cm *CustomBoundaryNormColormap) Alpha() float64    { return cm.alpha }
+func (cm *CustomBoundaryNormColormap) SetAlpha(a float64) { cm.alpha = a }
+
+// Palette samples Color(z) at numColors evenly spaced points across
+// [Min(), Max()] and returns them as a palette.Palette. plotter.HeatMap only
+// ever looks a value up by linearly interpolating across a uniform color
+// list, so baking the discrete boundary colors into a fine-grained table
+// here is what makes the banding actually show up in the rendered heatmap,
+// rather than being smoothed away.
 func (cm *CustomBoundaryNormColormap) Palette(numColors int) palette.Palette {
-	// This will NOT respect the boundaries in the same way as matplotlib's BoundaryNorm
-	// when used directly with HeatMap if HeatMap normalizes data itself.
-	// HeatMap needs a Min/Max set to correspond to the overall range of the custom colormap.
-	return palette.Palette(cm.Colors)
+	if numColors < 2 {
+		numColors = 2
+	}
+	colors := make([]color.Color, numColors)
+	span := cm.max - cm.min
+	for i := 0; i < numColors; i++ {
+		t := float64(i) / float64(numColors-1)
+		colors[i] = cm.Color(cm.min + t*span)
+	}
+	return palette.Palette(colors)
 }
 
+// newDeviationBoundaryColormap returns the red/orange/pale-yellow/green
+// boundary banding around +/-0.1, +/-0.5, +/-1.0mm used for the leaf
+// deviation heatmap. Min/Max must still be set by the caller.
+func newDeviationBoundaryColormap() *CustomBoundaryNormColormap {
+	return &CustomBoundaryNormColormap{
+		Boundaries: []float64{-1.0, -0.5, -0.1, 0.1, 0.5, 1.0}, // N+1 boundaries for N colors
+		Colors: []color.Color{
+			color.RGBA{R: 0xff, G: 0x7f, B: 0x0e, A: 255}, // Orange (-1.0 to -0.5)
+			color.RGBA{R: 0xdb, G: 0xdb, B: 0x8d, A: 255}, // PaleYellow (-0.5 to -0.1)
+			color.RGBA{R: 0x2c, G: 0xa0, B: 0x2c, A: 255}, // Green (-0.1 to 0.1)
+			color.RGBA{R: 0xdb, G: 0xdb, B: 0x8d, A: 255}, // PaleYellow (0.1 to 0.5)
+			color.RGBA{R: 0xff, G: 0x7f, B: 0x0e, A: 255}, // Orange (0.5 to 1.0)
+		},
+		UnderColor: color.RGBA{R: 0xd6, G: 0x27, B: 0x28, A: 255}, // DarkRed ( < -1.0)
+		OverColor:  color.RGBA{R: 0xd6, G: 0x27, B: 0x28, A: 255}, // DarkRed ( >= 1.0)
+		NaNColor:   color.Gray{Y: 200},
+	}
+}
+
+// CreateHeatmapPlot generates a heatmap for a given value column. paletteName
+// selects the color scheme via DefaultPaletteRegistry; pass "" to get each
+// metric's usual scheme. PaletteDefault selects the registry's "default"
+// heatmap palette for every metric.
+func CreateHeatmapPlot(analysisResults *analysis.AnalysisResults, valueColName string, plotTitle string, paletteName PaletteName, format PlotFormat) ([]byte, error) {
+	p, colorMap, hmMin, hmMax, err := buildHeatmapPlot(analysisResults, valueColName, plotTitle, paletteName)
+	if err != nil {
+		return nil, err
+	}
+	if colorMap == nil {
+		// No discrete boundaries defined for this metric; render the heatmap alone.
+		return renderPlot(p, DefaultHeatmapPlotSize, format)
+	}
+	return renderHeatmapWithColorBar(p, colorMap, hmMin, hmMax, DefaultHeatmapPlotSize, format)
+}
 
-// CreateHeatmapPlot generates a heatmap for a given value column.
-func CreateHeatmapPlot(analysisResults *analysis.AnalysisResults, valueColName string, plotTitle string) ([]byte, error) {
+// buildHeatmapPlot constructs the heatmap plot without rendering it, so
+// composite layouts (see CreateCompositeReport) can lay several plots out on
+// one canvas instead of each getting its own image. The returned colorMap is
+// non-nil only when the metric has a discrete boundary legend to draw.
+func buildHeatmapPlot(analysisResults *analysis.AnalysisResults, valueColName string, plotTitle string, paletteName PaletteName) (*plot.Plot, palette.ColorMap, float64, float64, error) {
 	if analysisResults == nil || len(analysisResults.Results) == 0 {
-		return nil, fmt.Errorf("no analysis results to plot heatmap")
+		return nil, nil, 0, 0, fmt.Errorf("no analysis results to plot heatmap")
 	}
 
 	bankDataMap := make(map[string]map[int]float64)
@@ -76,13 +145,13 @@ func CreateHeatmapPlot(analysisResults *analysis.AnalysisResults, valueColName s
 		case "Range (mm)":
 			val = res.PositionalRange
 		default:
-			return nil, fmt.Errorf("unknown value column for heatmap: %s", valueColName)
+			return nil, nil, 0, 0, fmt.Errorf("unknown value column for heatmap: %s", valueColName)
 		}
 		bankDataMap[res.BankName][res.LeafIndex] = val
 	}
 
 	if len(uniqueBankNames) == 0 {
-		 return nil, fmt.Errorf("no bank data found for heatmap")
+		 return nil, nil, 0, 0, fmt.Errorf("no bank data found for heatmap")
 	}
 
 	sortedBankNames := make([]string, 0, len(uniqueBankNames))
@@ -158,55 +227,34 @@ func CreateHeatmapPlot(analysisResults *analysis.AnalysisResults, valueColName s
 
 
 	var hm *plotter.HeatMap
+	var colorMap palette.ColorMap // set when the bucketed legend below should draw from it
 	fixedPlotVmax := 1.5
 	NaNColor := color.Gray{Y: 200} // Light gray for NaN
 
 	if valueColName == "Deviation (mm)" {
-		// Boundaries: [-fixed_plot_vmax, -1.0, -0.5, -0.1, 0.1, 0.5, 1.0, fixed_plot_vmax]
-		// Colors: ['#d62728', '#ff7f0e', '#dbdb8d', '#2ca02c', '#dbdb8d', '#ff7f0e', '#d62728']
-		customMap := CustomBoundaryNormColormap{
-			Boundaries: []float64{-1.0, -0.5, -0.1, 0.1, 0.5, 1.0}, // Inner N-1 boundaries for N colors
-			Colors: []color.Color{
-				color.RGBA{R: 0xff, G: 0x7f, B: 0x0e, A: 255}, // Orange (-1.0 to -0.5)
-				color.RGBA{R: 0xdb, G: 0xdb, B: 0x8d, A: 255}, // PaleYellow (-0.5 to -0.1)
-				color.RGBA{R: 0x2c, G: 0xa0, B: 0x2c, A: 255}, // Green (-0.1 to 0.1)
-				color.RGBA{R: 0xdb, G: 0xdb, B: 0x8d, A: 255}, // PaleYellow (0.1 to 0.5)
-				color.RGBA{R: 0xff, G: 0x7f, B: 0x0e, A: 255}, // Orange (0.5 to 1.0)
-			},
-			UnderColor: color.RGBA{R: 0xd6, G: 0x27, B: 0x28, A: 255}, // DarkRed ( < -1.0)
-			OverColor:  color.RGBA{R: 0xd6, G: 0x27, B: 0x28, A: 255}, // DarkRed ( >= 1.0)
-			NaNColor:   NaNColor,
+		// Defaults to the discrete +/-0.1/+/-0.5/+/-1.0mm boundary banding QA
+		// physicists expect around the +/-1.0mm action level; paletteName can
+		// override it with any other registered heatmap scheme.
+		pal, cm := DefaultPaletteRegistry.Heatmap(resolvePaletteName(paletteName, PaletteBoundaryMLC), -fixedPlotVmax, fixedPlotVmax)
+		if bnm, ok := cm.(*CustomBoundaryNormColormap); ok {
+			bnm.NaNColor = NaNColor
 		}
-		// The HeatMap needs its Min/Max set to the overall range of the colormap boundaries
-        // For BoundaryNorm, the HeatMap's Min/Max should encompass the full data range you want the colormap to span.
-        // The CustomPalette then maps specific values to colors based on these boundaries.
-        // Gonum's HeatMap may not directly support this type of norm.
-        // A workaround is to use a palette that has enough distinct colors and set Min/Max on HeatMap.
-        // For this example, we'll use a standard diverging palette and set Min/Max.
-
-		divPalette := palette.Reverse(palette.RdBu) // A common diverging palette
-        hm = plotter.NewHeatMap(gridData, divPalette)
+
+		hm = plotter.NewHeatMap(gridData, pal)
 		hm.Min = -fixedPlotVmax
 		hm.Max = fixedPlotVmax
 		hm.NaNOption = plotter.NaNColor{Color: NaNColor}
+		colorMap = cm
 
 	} else if valueColName == "Std Dev (mm)" || valueColName == "Range (mm)" {
-		// RdYlGn_r (Green low, Red high)
-		// Using a sequence of colors for RdYlGn_r type palette
-        // Green -> Yellow -> Orange -> Red
-        customColors := []color.Color{
-            color.RGBA{R:0, G:100, B:0, A:255},      // Dark Green
-            color.RGBA{R:0, G:255, B:0, A:255},      // Green
-            color.RGBA{R:255, G:255, B:0, A:255},    // Yellow
-            color.RGBA{R:255, G:165, B:0, A:255},    // Orange
-            color.RGBA{R:255, G:0, B:0, A:255},      // Red
-        }
-        pal := palette.NewPalette(customColors)
+		// RdYlGn_r by default (green low, red high).
+		pal, cm := DefaultPaletteRegistry.Heatmap(resolvePaletteName(paletteName, PaletteRdYlGn), 0, fixedPlotVmax)
 
 		hm = plotter.NewHeatMap(gridData, pal)
 		hm.Min = 0
 		hm.Max = fixedPlotVmax // Max for these metrics is often capped for visualization
 		hm.NaNOption = plotter.NaNColor{Color: NaNColor}
+		colorMap = cm
 	} else {
 		hm = plotter.NewHeatMap(gridData, palette.Viridis)
 		if len(allValidValues) > 0 {
@@ -221,33 +269,46 @@ func CreateHeatmapPlot(analysisResults *analysis.AnalysisResults, valueColName s
 	}
 	p.Add(hm)
 
-	// Color bar - In gonum/plot, this is often handled by adding a Legend.
-    // For heatmaps, a plotter.ColorBar can be created and potentially drawn on a separate plot or area.
-    // Adding it directly to the main plot 'p' needs careful placement.
-    // The example from the user implies direct saving, so color bar might be part of the image.
-    // plotter.NewLegend() might be used if we can make the HeatMap a "Thumbnailer".
-    // For now, we'll skip explicit color bar addition to the plot object 'p'
-    // as its API for this is not as straightforward as matplotlib for integrated color bars.
-	if hmPal, ok := hm.Palette.(plot.ColorMap); ok {
-		cb := plotter.NewColorBar(hmPal) // hm.Palette should implement plot.ColorMap
-		cb.Min = hm.Min
-		cb.Max = hm.Max
-		cb.Vertical = false // Horizontal
-		// p.Add(&cb) // This might not place it correctly without more layout hints.
-		// A common approach is to create a new plot for the colorbar if complex layout is needed.
-		// For this subtask, let's assume the heatmap itself is the primary output.
-		// The PDF generation step (later subtask) will need to handle compositing plots and color bars.
-		log.Printf("Color bar for %s: Min=%.2f, Max=%.2f. Manual placement in PDF needed.", plotTitle, hm.Min, hm.Max)
-	}
-
-
-	writer, err := p.WriterTo(vg.Points(1000), vg.Points(500), "png")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create heatmap writer: %v", err)
+	return p, colorMap, hm.Min, hm.Max, nil
+}
+
+// renderHeatmapWithColorBar draws the heatmap plot and a vertical color-bar
+// legend (with tick labels at the colormap's boundary values) side by side on
+// one canvas, so CreateHeatmapPlot returns a single self-contained image
+// instead of deferring the legend's layout to the caller.
+func renderHeatmapWithColorBar(p *plot.Plot, cm palette.ColorMap, min, max float64, size PlotSize, format PlotFormat) ([]byte, error) {
+	barPlot := plot.New()
+	barPlot.Y.Min = min
+	barPlot.Y.Max = max
+	if bnm, ok := cm.(*CustomBoundaryNormColormap); ok {
+		ticks := make([]plot.Tick, 0, len(bnm.Boundaries)+2)
+		ticks = append(ticks, plot.Tick{Value: min, Label: fmt.Sprintf("%.1f", min)})
+		for _, b := range bnm.Boundaries {
+			ticks = append(ticks, plot.Tick{Value: b, Label: fmt.Sprintf("%.1f", b)})
+		}
+		ticks = append(ticks, plot.Tick{Value: max, Label: fmt.Sprintf("%.1f", max)})
+		barPlot.Y.Tick.Marker = plot.ConstantTicks(ticks)
 	}
-	buf := new(bytes.Buffer)
-	if _, err := writer.WriteTo(buf); err != nil {
-		return nil, fmt.Errorf("failed to write heatmap to buffer: %v", err)
+
+	cb := plotter.NewColorBar(cm)
+	cb.Vertical = true
+	cb.Min = min
+	cb.Max = max
+	barPlot.Add(cb)
+
+	c, err := newPlotCanvas(size, format)
+	if err != nil {
+		return nil, err
 	}
-	return buf.Bytes(), nil
+	full := draw.New(c)
+	w, _ := full.Size()
+	barWidth := w * 0.08 // right-hand strip reserved for the color bar + its tick labels
+
+	mainCanvas := full.Crop(0, 0, -barWidth, 0)
+	barCanvas := full.Crop(w-barWidth, 0, 0, 0)
+
+	p.Draw(mainCanvas)
+	barPlot.Draw(barCanvas)
+
+	return encodePlotCanvas(c)
 }
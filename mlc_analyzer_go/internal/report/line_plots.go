@@ -1,7 +1,6 @@
 package report
 
 import (
-	"bytes"
 	"fmt"
 	"image/color"
 	"log"
@@ -18,8 +17,28 @@ import (
 	// "gonum.org/v1/plot/vg/draw" // Not used directly in the provided code
 )
 
-// CreateLinePlot generates a line plot for deviation or reproducibility.
-func CreateLinePlot(analysisResults *analysis.AnalysisResults, plotType string, bankFilter string, toleranceMM float64) ([]byte, error) {
+// CreateLinePlot generates a line plot for deviation or reproducibility, or,
+// for plotType == "boxplot", a per-leaf box plot of each measurement's
+// deviation from its bank's nominal setpoint via
+// CreateBoxPlot (bankFilter selects which banks the box plot merges, same as
+// the line plot's own bank filtering below).
+// paletteName selects the bank-series colors via DefaultPaletteRegistry;
+// pass "" (PaletteDefault) for the original red/green/blue/... set.
+func CreateLinePlot(analysisResults *analysis.AnalysisResults, plotType string, bankFilter string, toleranceMM float64, paletteName PaletteName, format PlotFormat) ([]byte, error) {
+	if plotType == "boxplot" {
+		return CreateBoxPlot(analysisResults, bankFilter, toleranceMM, format)
+	}
+	p, err := buildLinePlot(analysisResults, plotType, bankFilter, toleranceMM, paletteName)
+	if err != nil {
+		return nil, err
+	}
+	return renderPlot(p, DefaultLinePlotSize, format)
+}
+
+// buildLinePlot constructs the deviation/reproducibility line plot without
+// rendering it, so composite layouts (see CreateCompositeReport) can lay
+// several plots out on one canvas instead of each getting its own image.
+func buildLinePlot(analysisResults *analysis.AnalysisResults, plotType string, bankFilter string, toleranceMM float64, paletteName PaletteName) (*plot.Plot, error) {
 	if analysisResults == nil || len(analysisResults.Results) == 0 {
 		return nil, fmt.Errorf("no analysis results to plot")
 	}
@@ -93,14 +112,7 @@ func CreateLinePlot(analysisResults *analysis.AnalysisResults, plotType string,
 	sort.Strings(bankNames)
 
 
-	plotColors := []color.Color{
-		color.RGBA{R: 255, G: 0, B: 0, A: 255},    // Red
-		color.RGBA{G: 255, B: 0, A: 255},    // Green
-		color.RGBA{B: 255, A: 255},    // Blue
-		color.RGBA{R: 255, G: 165, B: 0, A: 255}, // Orange (fixed B value from 0 to B:0)
-		color.RGBA{R: 128, G: 0, B: 128, A: 255}, // Purple
-		color.RGBA{G: 128, B: 128, A: 255}, // Teal
-	}
+	plotColors := DefaultPaletteRegistry.Qualitative(resolvePaletteName(paletteName, PaletteDefault))
 	colorIndex := 0
 
 	linesPlotted := false
@@ -158,15 +170,7 @@ func CreateLinePlot(analysisResults *analysis.AnalysisResults, plotType string,
 	p.Legend.Top = true
 	p.Legend.XOffs = vg.Points(10)
 
-	writer, err := p.WriterTo(vg.Points(800), vg.Points(400), "png")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create plot writer: %v", err)
-	}
-	buf := new(bytes.Buffer)
-	if _, err := writer.WriteTo(buf); err != nil {
-		return nil, fmt.Errorf("failed to write plot to buffer: %v", err)
-	}
-	return buf.Bytes(), nil
+	return p, nil
 }
 
 // generateTicks creates a slice of plot.Tick for major ticks.
@@ -0,0 +1,142 @@
+package report
+
+import (
+	"image/color"
+
+	"gonum.org/v1/plot/palette"
+)
+
+// PaletteName identifies a named entry in a PaletteRegistry. The zero value
+// ("") means "use this metric's own default", resolved via resolvePaletteName.
+type PaletteName string
+
+const (
+	PaletteDefault     PaletteName = "default"
+	PaletteColorblind  PaletteName = "colorblind"
+	PaletteViridis     PaletteName = "viridis"
+	PaletteRdYlGn      PaletteName = "rdylgn"
+	PaletteBoundaryMLC PaletteName = "boundary-mlc"
+)
+
+// resolvePaletteName maps the zero value ("") to a metric's own usual
+// palette, while letting an explicit request (including PaletteDefault)
+// through unchanged.
+func resolvePaletteName(requested, metricDefault PaletteName) PaletteName {
+	if requested == "" {
+		return metricDefault
+	}
+	return requested
+}
+
+// heatmapPaletteFunc builds a palette.Palette sized to [min, max], plus a
+// palette.ColorMap when the scheme has boundary values worth drawing a
+// color-bar legend for (see renderHeatmapWithColorBar); nil otherwise.
+type heatmapPaletteFunc func(min, max float64) (palette.Palette, palette.ColorMap)
+
+// PaletteRegistry resolves a PaletteName to concrete colors for both
+// line-plot bank series (qualitative) and heatmaps (sequential/diverging),
+// so a CLI/UI can offer a colorblind-safe option globally without touching
+// plotting code.
+type PaletteRegistry struct {
+	qualitative map[PaletteName][]color.Color
+	heatmap     map[PaletteName]heatmapPaletteFunc
+}
+
+// NewPaletteRegistry builds a registry pre-populated with the built-in
+// palette set ("default", "colorblind", "viridis", "rdylgn", "boundary-mlc").
+func NewPaletteRegistry() *PaletteRegistry {
+	r := &PaletteRegistry{
+		qualitative: make(map[PaletteName][]color.Color),
+		heatmap:     make(map[PaletteName]heatmapPaletteFunc),
+	}
+
+	r.qualitative[PaletteDefault] = []color.Color{
+		color.RGBA{R: 255, A: 255},                // Red
+		color.RGBA{G: 255, A: 255},                // Green
+		color.RGBA{B: 255, A: 255},                // Blue
+		color.RGBA{R: 255, G: 165, A: 255},         // Orange
+		color.RGBA{R: 128, B: 128, A: 255},         // Purple
+		color.RGBA{G: 128, B: 128, A: 255},         // Teal
+	}
+	// Okabe-Ito: the standard 8-color qualitative set distinguishable under
+	// the common forms of color vision deficiency.
+	r.qualitative[PaletteColorblind] = []color.Color{
+		color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 255}, // Black
+		color.RGBA{R: 0xE6, G: 0x9F, B: 0x00, A: 255}, // Orange
+		color.RGBA{R: 0x56, G: 0xB4, B: 0xE9, A: 255}, // Sky blue
+		color.RGBA{R: 0x00, G: 0x9E, B: 0x73, A: 255}, // Bluish green
+		color.RGBA{R: 0xF0, G: 0xE4, B: 0x42, A: 255}, // Yellow
+		color.RGBA{R: 0x00, G: 0x72, B: 0xB2, A: 255}, // Blue
+		color.RGBA{R: 0xD5, G: 0x5E, B: 0x00, A: 255}, // Vermillion
+		color.RGBA{R: 0xCC, G: 0x79, B: 0xA7, A: 255}, // Reddish purple
+	}
+	r.qualitative[PaletteViridis] = samplePaletteColors(palette.Viridis, 8)
+
+	r.heatmap[PaletteDefault] = func(min, max float64) (palette.Palette, palette.ColorMap) {
+		return palette.Reverse(palette.RdBu), nil
+	}
+	r.heatmap[PaletteViridis] = func(min, max float64) (palette.Palette, palette.ColorMap) {
+		return palette.Viridis, nil
+	}
+	r.heatmap[PaletteRdYlGn] = func(min, max float64) (palette.Palette, palette.ColorMap) {
+		colors := []color.Color{
+			color.RGBA{R: 0, G: 100, A: 255},   // Dark Green
+			color.RGBA{G: 255, A: 255},         // Green
+			color.RGBA{R: 255, G: 255, A: 255}, // Yellow
+			color.RGBA{R: 255, G: 165, A: 255}, // Orange
+			color.RGBA{R: 255, A: 255},         // Red
+		}
+		return palette.NewPalette(colors), nil
+	}
+	r.heatmap[PaletteBoundaryMLC] = func(min, max float64) (palette.Palette, palette.ColorMap) {
+		cm := newDeviationBoundaryColormap()
+		cm.SetMin(min)
+		cm.SetMax(max)
+		return cm.Palette(256), cm
+	}
+	// The boundary banding's hues (dark red / orange / pale yellow / green)
+	// already read correctly under protanopia/deuteranopia, so reuse it here
+	// rather than inventing a second discrete scheme.
+	r.heatmap[PaletteColorblind] = r.heatmap[PaletteBoundaryMLC]
+
+	return r
+}
+
+// Qualitative returns the ordered line-series colors for name, falling back
+// to PaletteDefault for an unregistered name.
+func (r *PaletteRegistry) Qualitative(name PaletteName) []color.Color {
+	if colors, ok := r.qualitative[name]; ok {
+		return colors
+	}
+	return r.qualitative[PaletteDefault]
+}
+
+// Heatmap returns the palette.Palette (scaled to [min, max]) used to drive a
+// plotter.HeatMap for name, falling back to PaletteDefault for an
+// unregistered name.
+func (r *PaletteRegistry) Heatmap(name PaletteName, min, max float64) (palette.Palette, palette.ColorMap) {
+	if fn, ok := r.heatmap[name]; ok {
+		return fn(min, max)
+	}
+	return r.heatmap[PaletteDefault](min, max)
+}
+
+func samplePaletteColors(p palette.Palette, n int) []color.Color {
+	base := p.Colors()
+	if len(base) == 0 || n <= 0 {
+		return nil
+	}
+	out := make([]color.Color, n)
+	denom := n - 1
+	if denom < 1 {
+		denom = 1
+	}
+	for i := 0; i < n; i++ {
+		out[i] = base[i*(len(base)-1)/denom]
+	}
+	return out
+}
+
+// DefaultPaletteRegistry is the process-wide palette set used by
+// CreateLinePlot and CreateHeatmapPlot.
+var DefaultPaletteRegistry = NewPaletteRegistry()
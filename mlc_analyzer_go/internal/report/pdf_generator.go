@@ -3,10 +3,14 @@ package report
 import (
 	"bytes"
 	"fmt"
+	"image"
+	_ "image/png" // registers the PNG decoder used by image.DecodeConfig
 	"log"
 	"math"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/jung-kurt/gofpdf"
 	"github.com/user/mlc_analyzer_go/internal/analysis"
@@ -14,36 +18,162 @@ import (
 )
 
 const (
-	inchToMm               = 25.4
-	pdfPageWidthLandscape  = 11 * inchToMm // Letter landscape
-	pdfPageHeightLandscape = 8.5 * inchToMm
-	pdfMargin              = 0.5 * inchToMm
-	pdfContentWidth        = pdfPageWidthLandscape - (2 * pdfMargin)
+	inchToMm        = 25.4
+	pdfDefaultMargin = 0.5 * inchToMm
+
+	// pdfHeaderBandMM/pdfFooterBandMM keep body content clear of the running
+	// header/footer installed by pdfStyler.installHeaderFooter, so
+	// checkAddPage never lets body content collide with them. The header is
+	// drawn in the band below the top margin; the footer is drawn
+	// pdfFooterBandMM above the page's bottom edge, inside the bottom margin.
+	pdfHeaderBandMM = 10
+	pdfFooterBandMM = 10
 )
 
+// PageConfig controls the generated PDF's page geometry. The zero value
+// falls back to the report's historical default: Letter-size landscape with
+// a 0.5in margin.
+type PageConfig struct {
+	Size        string  // gofpdf size string: "Letter", "A4", "Legal", ...
+	Orientation string  // "L" (landscape) or "P" (portrait)
+	MarginMM    float64 // 0 uses pdfDefaultMargin
+}
+
+func (c PageConfig) withDefaults() PageConfig {
+	if c.Size == "" {
+		c.Size = "Letter"
+	}
+	if c.Orientation == "" {
+		c.Orientation = "L"
+	}
+	if c.MarginMM == 0 {
+		c.MarginMM = pdfDefaultMargin
+	}
+	return c
+}
+
+// ReportMeta carries per-report provenance - which machine, who ran it, when,
+// and against which source file - printed in every page's header/footer so a
+// single printed page remains traceable even if separated from the rest of
+// the report.
+type ReportMeta struct {
+	Institution    string
+	MachineID      string
+	Operator       string
+	GeneratedAt    time.Time
+	SourceFileName string
+}
+
+// ProtectionOptions password-protects and restricts permissions on the
+// generated PDF, for reports distributed outside the clinic that shouldn't
+// be freely reprinted, copied, or edited. A non-empty UserPassword is
+// required to open the document at all; OwnerPassword is required to change
+// permissions or remove protection. Only a nil *ProtectionOptions gives an
+// unprotected PDF (the default): a non-nil one always encrypts the PDF and
+// applies the Allow* permissions, even with both passwords empty.
+// OverwriteExisting must be set to replace a file that already exists at the
+// destination path once protection is requested, as a guard against
+// accidentally clobbering a previously distributed, password-protected report.
+type ProtectionOptions struct {
+	UserPassword      string
+	OwnerPassword     string
+	AllowPrint        bool
+	AllowCopy         bool
+	AllowModify       bool
+	AllowAnnotate     bool
+	OverwriteExisting bool
+}
+
+func (o ProtectionOptions) permissions() byte {
+	var p byte
+	if o.AllowPrint {
+		p |= gofpdf.CnProtectPrint
+	}
+	if o.AllowCopy {
+		p |= gofpdf.CnProtectCopy
+	}
+	if o.AllowModify {
+		p |= gofpdf.CnProtectModify
+	}
+	if o.AllowAnnotate {
+		p |= gofpdf.CnProtectAnnotForms
+	}
+	return p
+}
+
 // pdfStyler holds reusable styling and state for PDF generation
 type pdfStyler struct {
-	pdf         *gofpdf.Fpdf
-	styles      map[string]func() // map of style name to function that sets font, color etc.
-	lineHeight  float64
-	currentY    float64 // To manually track Y position for flowing content
-	pageHeight  float64
-	contentTopY float64 // Top Y after margin
+	pdf          *gofpdf.Fpdf
+	styles       map[string]func() // map of style name to function that sets font, color etc.
+	lineHeight   float64
+	currentY     float64 // To manually track Y position for flowing content
+	pageHeight   float64 // Max Y content may reach before a new page is needed
+	contentTopY  float64 // Top Y after margin and header band
+	marginMM     float64
+	contentWidth float64 // Page width minus both side margins
+
+	// tableHeaderTpl and tableHeaderHeight hold the currently active ranking/
+	// out-of-tolerance table header, set by setTableHeaderTemplate and
+	// re-stamped on every page break by repeatTableHeaderOnBreak. nil when no
+	// table header is active.
+	tableHeaderTpl    gofpdf.Template
+	tableHeaderHeight float64
+
+	// captionFrameTpl is the shared border frame stamped under every image
+	// caption, created once in newPDFStyler.
+	captionFrameTpl gofpdf.Template
 }
 
-func newPDFStyler(pdf *gofpdf.Fpdf) *pdfStyler {
+// newPDFStyler derives its layout from the page's actual current size via
+// pdf.GetPageSize(), so it works for any PageConfig rather than assuming
+// Letter landscape.
+func newPDFStyler(pdf *gofpdf.Fpdf, marginMM float64) *pdfStyler {
+	pageWidth, pageHeight := pdf.GetPageSize()
 	s := &pdfStyler{
-		pdf:         pdf,
-		styles:      make(map[string]func()),
-		lineHeight:  6, // mm, default line height
-		pageHeight:  pdfPageHeightLandscape - (2 * pdfMargin), // Usable height
-		contentTopY: pdfMargin,
+		pdf:          pdf,
+		styles:       make(map[string]func()),
+		lineHeight:   6, // mm, default line height
+		marginMM:     marginMM,
+		contentWidth: pageWidth - (2 * marginMM),
+		pageHeight:   pageHeight - marginMM - pdfFooterBandMM, // Usable height, reserving the footer band
+		contentTopY:  marginMM + pdfHeaderBandMM,
 	}
 	s.currentY = s.contentTopY
 	s.defineStyles()
+	s.captionFrameTpl = newCaptionFrameTemplate(pdf)
 	return s
 }
 
+// installHeaderFooter wires gofpdf's SetHeaderFunc/SetFooterFunc so every
+// page carries a title/provenance header and a "Page N/{nb}" footer with the
+// tolerance and institution/machine ID, and disables gofpdf's own automatic
+// page breaks so pdfStyler.checkAddPage remains the only thing that decides
+// when a new page starts. Must be called before the first AddPage.
+func (s *pdfStyler) installHeaderFooter(meta ReportMeta, toleranceMM float64) {
+	s.pdf.SetAutoPageBreak(false, 0)
+	s.pdf.AliasNbPages("")
+
+	s.pdf.SetHeaderFunc(func() {
+		s.pdf.SetY(s.marginMM)
+		s.pdf.SetFont("Arial", "B", 10)
+		s.pdf.SetTextColor(80, 80, 80)
+		headerText := fmt.Sprintf("MLC Leaf Reproducibility Report - %s - %s",
+			meta.SourceFileName, meta.GeneratedAt.Format("2006-01-02 15:04"))
+		s.pdf.CellFormat(s.contentWidth, s.lineHeight, headerText, "", 0, "C", false, 0, "")
+	})
+
+	s.pdf.SetFooterFunc(func() {
+		s.pdf.SetY(-pdfFooterBandMM)
+		s.pdf.SetFont("Arial", "I", 8)
+		s.pdf.SetTextColor(100, 100, 100)
+		footerText := fmt.Sprintf("Page %d/{nb}  |  Tolerance: +/- %.1f mm", s.pdf.PageNo(), toleranceMM)
+		if meta.Institution != "" || meta.MachineID != "" {
+			footerText += fmt.Sprintf("  |  %s %s", meta.Institution, meta.MachineID)
+		}
+		s.pdf.CellFormat(s.contentWidth, s.lineHeight, footerText, "", 0, "C", false, 0, "")
+	})
+}
+
 func (s *pdfStyler) defineStyles() {
 	s.styles["h1"] = func() {
 		s.pdf.SetFont("Arial", "B", 16)
@@ -80,10 +210,16 @@ func (s *pdfStyler) applyStyle(styleName string) {
 	}
 }
 
+// checkAddPage starts a new page once neededHeight would overflow the usable
+// page height. repeatTableHeaderOnBreak re-stamps the active table header
+// template (see setTableHeaderTemplate in templates.go) on the fresh page, so
+// a per-row checkAddPage call mid-table never leaves a data row stranded
+// under no header.
 func (s *pdfStyler) checkAddPage(neededHeight float64) {
 	if s.currentY+neededHeight > s.pageHeight {
 		s.pdf.AddPage()
 		s.currentY = s.contentTopY
+		s.repeatTableHeaderOnBreak()
 	}
 }
 
@@ -97,8 +233,8 @@ func (s *pdfStyler) writeParagraph(text string, styleName string, align string)
 
 	s.checkAddPage(estimatedHeight) // Use estimated height
 
-	s.pdf.SetXY(pdfMargin, s.currentY)
-	s.pdf.MultiCell(pdfContentWidth, s.lineHeight, text, "", align, false)
+	s.pdf.SetXY(s.marginMM, s.currentY)
+	s.pdf.MultiCell(s.contentWidth, s.lineHeight, text, "", align, false)
 	s.currentY = s.pdf.GetY() // Update Y based on what MultiCell consumed
 	s.currentY += 1           // Small gap after paragraph
 }
@@ -112,6 +248,17 @@ func (s *pdfStyler) addSpacer(height float64) {
 	}
 }
 
+// pngDimensions decodes just the header of a PNG to recover its pixel
+// dimensions, so callers can size placements to the image's real aspect
+// ratio instead of guessing.
+func pngDimensions(imageBytes []byte) (width, height int, err error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(imageBytes))
+	if err != nil {
+		return 0, 0, err
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
 func (s *pdfStyler) addImage(imageBytes []byte, imageName string, width float64, height float64, caption string, styleName string) {
 	// Use imageName as the unique key for registration.
 	// Gofpdf uses this name to refer to the image data later.
@@ -119,17 +266,18 @@ func (s *pdfStyler) addImage(imageBytes []byte, imageName string, width float64,
 	// No direct info returned on error by RegisterImageReader, relies on Image later
 
 	if width == 0 && height == 0 { // Basic auto-size placeholder
-		// Actual image dimensions are not easily available from RegisterImageReader
-		// For robust auto-sizing, image metadata (width/height) would be needed beforehand.
-		// For now, assume a default or require width/height.
-		width = pdfContentWidth / 2 // Default to half content width
-		height = width * (3.0 / 4.0) // Assume 4:3 aspect ratio
-		log.Printf("Warning: Auto-sizing image %s, using default dimensions. Provide explicit dimensions for best results.", imageName)
+		width = s.contentWidth / 2 // Default to half content width
+		if pxW, pxH, derr := pngDimensions(imageBytes); derr == nil && pxW > 0 {
+			height = width * float64(pxH) / float64(pxW)
+		} else {
+			height = width * (3.0 / 4.0) // Fall back to assuming 4:3
+			log.Printf("Warning: could not read dimensions for image %s (%v), assuming 4:3 aspect ratio.", imageName, derr)
+		}
 	}
 
-	if width > pdfContentWidth {
-		ratio := pdfContentWidth / width
-		width = pdfContentWidth
+	if width > s.contentWidth {
+		ratio := s.contentWidth / width
+		width = s.contentWidth
 		height *= ratio
 	}
 
@@ -140,26 +288,105 @@ func (s *pdfStyler) addImage(imageBytes []byte, imageName string, width float64,
 	}
 	s.checkAddPage(height + captionHeight)
 
-	s.pdf.Image(imageName, pdfMargin, s.currentY, width, height, false, "PNG", 0, "")
+	s.pdf.Image(imageName, s.marginMM, s.currentY, width, height, false, "PNG", 0, "")
 	s.currentY += height
 
 	if caption != "" {
 		s.addSpacer(1)
+		s.stampCaptionFrame(s.marginMM, s.currentY, s.contentWidth, s.lineHeight+1)
 		s.writeParagraph(caption, styleName, "C") // Centered caption
 	}
 	s.addSpacer(2)
 }
 
-// BuildPDFReport creates the PDF report.
+// ImageSpec is one image placed by addImageGrid.
+type ImageSpec struct {
+	Bytes   []byte
+	Name    string
+	Caption string
+}
+
+// addImageGrid lays out images cols-per-row on the page, left to right then
+// top to bottom, each cell contentWidth/cols wide with its height scaled to
+// preserve that image's own aspect ratio. This replaces placing one image
+// per page when several images (e.g. the overall heatmaps) are small enough
+// to share a row.
+func (s *pdfStyler) addImageGrid(images []ImageSpec, cols int) {
+	if cols < 1 {
+		cols = 1
+	}
+	cellWidth := s.contentWidth / float64(cols)
+
+	for rowStart := 0; rowStart < len(images); rowStart += cols {
+		rowEnd := rowStart + cols
+		if rowEnd > len(images) {
+			rowEnd = len(images)
+		}
+		row := images[rowStart:rowEnd]
+
+		cellHeights := make([]float64, len(row))
+		rowHeight := 0.0
+		for i, img := range row {
+			h := cellWidth * (3.0 / 4.0)
+			if pxW, pxH, err := pngDimensions(img.Bytes); err == nil && pxW > 0 {
+				h = cellWidth * float64(pxH) / float64(pxW)
+			}
+			cellHeights[i] = h
+			if h > rowHeight {
+				rowHeight = h
+			}
+		}
+
+		captionHeight := s.lineHeight + 1
+		s.checkAddPage(rowHeight + captionHeight)
+		rowTopY := s.currentY
+
+		x := s.marginMM
+		for i, img := range row {
+			s.pdf.RegisterImageReader(img.Name, "PNG", bytes.NewReader(img.Bytes))
+			s.pdf.Image(img.Name, x, rowTopY, cellWidth, cellHeights[i], false, "PNG", 0, "")
+			if img.Caption != "" {
+				s.stampCaptionFrame(x, rowTopY+cellHeights[i]+1, cellWidth, s.lineHeight)
+				s.pdf.SetXY(x, rowTopY+cellHeights[i]+1)
+				s.applyStyle("normal")
+				s.pdf.CellFormat(cellWidth, s.lineHeight, img.Caption, "", 0, "C", false, 0, "")
+			}
+			x += cellWidth
+		}
+		s.currentY = rowTopY + rowHeight + captionHeight + 2
+	}
+}
+
+// BuildPDFReport creates the PDF report. meta is printed in the header/footer
+// of every page so a single page remains traceable even if separated from
+// the rest of the report. protection may be nil for an unprotected PDF.
+// pageConfig's zero value produces the report's historical Letter-landscape
+// layout. The generated PDF carries a navigable outline (bookmarks) for the
+// summary, out-of-tolerance table (with one sub-bookmark per affected leaf),
+// each ranking table, the heatmaps, and the per-bank detail sections.
 func BuildPDFReport(filepath string, analysisResults *analysis.AnalysisResults,
-	numRuns int, toleranceMM float64, plotImages map[string][]byte) error {
+	numRuns int, toleranceMM float64, plotImages map[string][]byte, meta ReportMeta,
+	protection *ProtectionOptions, pageConfig PageConfig) error {
 
-	pdf := gofpdf.New("L", "mm", "Letter", "") // Landscape, mm, Letter size
-	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
-	pdf.AddPage()
+	if protection != nil {
+		if _, err := os.Stat(filepath); err == nil && !protection.OverwriteExisting {
+			return fmt.Errorf("refusing to overwrite existing file %q with a protected report; set ProtectionOptions.OverwriteExisting to confirm", filepath)
+		}
+	}
 
-	styler := newPDFStyler(pdf)
+	pageConfig = pageConfig.withDefaults()
+	pdf := gofpdf.New(pageConfig.Orientation, "mm", pageConfig.Size, "")
+	pdf.SetMargins(pageConfig.MarginMM, pageConfig.MarginMM, pageConfig.MarginMM)
 
+	if protection != nil {
+		pdf.SetProtection(protection.permissions(), protection.UserPassword, protection.OwnerPassword)
+	}
+
+	styler := newPDFStyler(pdf, pageConfig.MarginMM)
+	styler.installHeaderFooter(meta, toleranceMM)
+	pdf.AddPage()
+
+	pdf.Bookmark("Summary", 0, -1)
 	styler.writeParagraph(fmt.Sprintf("MLC Leaf Reproducibility and Accuracy Report (%d Runs)", numRuns), "h1", "C")
 	styler.addSpacer(5)
 	styler.writeParagraph(fmt.Sprintf("Tolerance: +/- %.1f mm", toleranceMM), "normal", "L")
@@ -177,32 +404,23 @@ func BuildPDFReport(filepath string, analysisResults *analysis.AnalysisResults,
 		}
 	}
 
+	pdf.Bookmark(fmt.Sprintf("Leaves Exceeding Tolerance (+/- %.1f mm)", toleranceMM), 0, -1)
 	styler.writeParagraph(fmt.Sprintf("Leaves Exceeding Tolerance (+/- %.1f mm)", toleranceMM), "h2", "L")
 	if len(outOfToleranceLeaves) > 0 {
 		headers := []string{"Bank", "Leaf ID", "Nominal (mm)", "Mean Pos (mm)", "Deviation (mm)"}
 		colWidthsRel := []float64{0.35, 0.1, 0.15, 0.2, 0.2}
 		colWidthsAbs := make([]float64, len(colWidthsRel))
 		for i, rel := range colWidthsRel {
-			colWidthsAbs[i] = rel * pdfContentWidth
+			colWidthsAbs[i] = rel * styler.contentWidth
 		}
 
 		// Estimate height for table + header
 		tableHeightNeeded := styler.lineHeight * (float64(len(outOfToleranceLeaves)) + 1.0)
 		styler.checkAddPage(tableHeightNeeded)
 
-		sY := styler.currentY
-		sX := pdfMargin
-		styler.applyStyle("tableHeader")
-		for i, header := range headers {
-			styler.pdf.SetXY(sX, sY)
-			styler.pdf.CellFormat(colWidthsAbs[i], styler.lineHeight, header, "1", 0, "C", true, 0, "")
-			sX += colWidthsAbs[i]
-		}
-		sY += styler.lineHeight
-		styler.currentY = sY
+		styler.setTableHeaderTemplate(headers, colWidthsAbs)
 
 		for _, leaf := range outOfToleranceLeaves {
-			sX = pdfMargin
 			rowData := []string{
 				leaf.BankName,
 				leaf.LeafID,
@@ -210,8 +428,10 @@ func BuildPDFReport(filepath string, analysisResults *analysis.AnalysisResults,
 				fmt.Sprintf("%.3f", leaf.MeanPosition),
 				fmt.Sprintf("%.3f", leaf.Deviation),
 			}
-			styler.checkAddPage(styler.lineHeight) // Check for each row
-			sY = styler.currentY // Potentially new Y if page break occurred
+			styler.checkAddPage(styler.lineHeight) // Check for each row; re-stamps the header on a break
+			sY := styler.currentY
+			sX := styler.marginMM
+			pdf.Bookmark(fmt.Sprintf("%s %s", leaf.BankName, leaf.LeafID), 1, sY)
 
 			for i, cellData := range rowData {
 				styler.pdf.SetXY(sX, sY)
@@ -226,6 +446,7 @@ func BuildPDFReport(filepath string, analysisResults *analysis.AnalysisResults,
 			sY += styler.lineHeight
 			styler.currentY = sY
 		}
+		styler.clearTableHeaderTemplate()
 	} else {
 		styler.writeParagraph(fmt.Sprintf("No leaves exceeded the +/- %.1f mm tolerance.", toleranceMM), "normal", "L")
 	}
@@ -237,7 +458,7 @@ func BuildPDFReport(filepath string, analysisResults *analysis.AnalysisResults,
 	rankColWidthsRel := []float64{0.1, 0.15, 0.45, 0.3}
 	rankColWidthsAbs := make([]float64, len(rankColWidthsRel))
 	for i, rel := range rankColWidthsRel {
-		rankColWidthsAbs[i] = rel * pdfContentWidth
+		rankColWidthsAbs[i] = rel * styler.contentWidth
 	}
 
 	rankings := []struct {
@@ -251,6 +472,7 @@ func BuildPDFReport(filepath string, analysisResults *analysis.AnalysisResults,
 	}
 
 	for _, rankSet := range rankings {
+		pdf.Bookmark(rankSet.Title, 0, -1)
 		styler.writeParagraph(rankSet.Title, "h2", "L")
 		if len(rankSet.Data) > 0 {
 			currentHeaders := make([]string, len(rankHeaders))
@@ -261,30 +483,21 @@ func BuildPDFReport(filepath string, analysisResults *analysis.AnalysisResults,
 			tableHeightNeeded := styler.lineHeight * (numRowsInTable + 1.0)
 			styler.checkAddPage(tableHeightNeeded)
 
-			sY := styler.currentY
-			sX := pdfMargin
-			styler.applyStyle("tableHeader")
-			for i, header := range currentHeaders {
-				styler.pdf.SetXY(sX, sY)
-				styler.pdf.CellFormat(rankColWidthsAbs[i], styler.lineHeight, header, "1", 0, "C", true, 0, "")
-				sX += rankColWidthsAbs[i]
-			}
-			sY += styler.lineHeight
-			styler.currentY = sY
+			styler.setTableHeaderTemplate(currentHeaders, rankColWidthsAbs)
 
 			for i, item := range rankSet.Data {
 				if i >= 10 {
 					break
 				} // Top 10
-				sX = pdfMargin
 				rowData := []string{
 					strconv.Itoa(i + 1),
 					item.LeafID,
 					item.BankName,
 					fmt.Sprintf("%.3f", item.Value),
 				}
-                styler.checkAddPage(styler.lineHeight) // Check for each row
-                sY = styler.currentY // Potentially new Y
+				styler.checkAddPage(styler.lineHeight) // Check for each row; re-stamps the header on a break
+				sY := styler.currentY
+				sX := styler.marginMM
 
 				styler.applyStyle("tableCell")
 				for j, cellData := range rowData {
@@ -295,6 +508,7 @@ func BuildPDFReport(filepath string, analysisResults *analysis.AnalysisResults,
 				sY += styler.lineHeight
 				styler.currentY = sY
 			}
+			styler.clearTableHeaderTemplate()
 		} else {
 			styler.writeParagraph(fmt.Sprintf("No data for %s.", strings.ToLower(rankSet.Title)), "normal", "L")
 		}
@@ -303,6 +517,7 @@ func BuildPDFReport(filepath string, analysisResults *analysis.AnalysisResults,
 	styler.pdf.AddPage()
 	styler.currentY = styler.contentTopY
 
+	pdf.Bookmark("Graphical Analysis", 0, -1)
 	styler.writeParagraph("Graphical Analysis", "h1", "C")
 	styler.addSpacer(5)
 
@@ -316,29 +531,23 @@ func BuildPDFReport(filepath string, analysisResults *analysis.AnalysisResults,
 		{"heatmap_range", "Overall Positional Range (Max - Min) Heatmap", "Heatmap of Leaf Positional Range (Max - Min, in mm)"},
 	}
 
-	imgWidth := pdfContentWidth * 0.9
-	imgHeight := imgWidth * (3.8 / 10.0)
-
-	for i, pDef := range plotDefs {
-		styler.writeParagraph(pDef.Title, "h2", "L")
+	heatmapImages := make([]ImageSpec, 0, len(plotDefs))
+	for _, pDef := range plotDefs {
 		if imgBytes, ok := plotImages[pDef.Key]; ok && len(imgBytes) > 0 {
-			styler.addImage(imgBytes, pDef.Key, imgWidth, imgHeight, pDef.Caption, "normal")
+			heatmapImages = append(heatmapImages, ImageSpec{Bytes: imgBytes, Name: pDef.Key, Caption: pDef.Caption})
 		} else {
 			styler.writeParagraph(fmt.Sprintf("Plot for %s not available.", pDef.Title), "normal", "L")
 		}
-		styler.addSpacer(2)
-		if (i+1) < len(plotDefs) && (i+1)%1 == 0 { // Add page break before next heatmap if not the last, make it 1 per page
-			styler.pdf.AddPage()
-			styler.currentY = styler.contentTopY
-		}
 	}
+	styler.addImageGrid(heatmapImages, 2)
 
-	linePlotImgWidth := pdfContentWidth * 0.8
+	linePlotImgWidth := styler.contentWidth * 0.8
 	linePlotImgHeight := linePlotImgWidth * (3.5 / 9.0)
 
 	for _, bankKeyword := range []string{"Left", "Right"} {
 		styler.pdf.AddPage()
 		styler.currentY = styler.contentTopY
+		pdf.Bookmark(fmt.Sprintf("%s Bank Detail", bankKeyword), 0, -1)
 		styler.writeParagraph(fmt.Sprintf("Detailed Plots: %s Bank", bankKeyword), "h2", "L")
 
 		devPlotKey := fmt.Sprintf("line_deviation_%s", strings.ToLower(bankKeyword))
@@ -357,6 +566,15 @@ func BuildPDFReport(filepath string, analysisResults *analysis.AnalysisResults,
 		} else {
 			styler.writeParagraph(fmt.Sprintf("Reproducibility plot for %s Bank not available.", bankKeyword), "normal", "L")
 		}
+		styler.addSpacer(5)
+
+		boxPlotKey := fmt.Sprintf("boxplot_%s", bankKeyword)
+		boxPlotCaption := fmt.Sprintf("%s Bank Per-Leaf Reproducibility (Box Plot)", bankKeyword)
+		if imgBytes, ok := plotImages[boxPlotKey]; ok && len(imgBytes) > 0 {
+			styler.addImage(imgBytes, boxPlotKey, linePlotImgWidth, linePlotImgHeight, boxPlotCaption, "normal")
+		} else {
+			styler.writeParagraph(fmt.Sprintf("Box plot for %s Bank not available.", bankKeyword), "normal", "L")
+		}
 	}
 
 	return pdf.OutputFileAndClose(filepath)
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+	"gonum.org/v1/plot/vg/vgpdf"
+	"gonum.org/v1/plot/vg/vgsvg"
+)
+
+// PlotFormat selects the output encoding used when a plot is rendered.
+type PlotFormat int
+
+const (
+	PlotFormatPNG PlotFormat = iota
+	PlotFormatSVG
+	PlotFormatPDF
+)
+
+// PlotSize gives the physical dimensions of a rendered plot in millimetres,
+// matching the units reports and the PDF builder already work in (see
+// PageConfig in pdf_generator.go).
+type PlotSize struct {
+	WidthMM  float64
+	HeightMM float64
+}
+
+// Default sizes, chosen to match the point dimensions the plots used before
+// gaining explicit physical units (800x400pt and 1000x500pt respectively).
+var (
+	DefaultLinePlotSize    = PlotSize{WidthMM: 282, HeightMM: 141}
+	DefaultHeatmapPlotSize = PlotSize{WidthMM: 353, HeightMM: 176}
+)
+
+// vgCanvasWriter is satisfied by the vgimg/vgsvg/vgpdf canvas types: each can
+// be drawn on via draw.New and then serialized with WriteTo.
+type vgCanvasWriter interface {
+	vg.Canvas
+	WriteTo(w io.Writer) (int64, error)
+}
+
+// newPlotCanvas allocates a drawing surface of the given physical size for
+// the requested format. Callers draw onto draw.New(canvas) (or crop it into
+// sub-regions for composite layouts) and then call encodePlotCanvas.
+func newPlotCanvas(size PlotSize, format PlotFormat) (vgCanvasWriter, error) {
+	width := vg.Length(size.WidthMM) * vg.Millimeter
+	height := vg.Length(size.HeightMM) * vg.Millimeter
+
+	switch format {
+	case PlotFormatSVG:
+		return vgsvg.New(width, height), nil
+	case PlotFormatPDF:
+		return vgpdf.New(width, height), nil
+	case PlotFormatPNG:
+		return vgimg.PngCanvas{Canvas: vgimg.New(width, height)}, nil
+	default:
+		return nil, fmt.Errorf("unknown plot format: %d", format)
+	}
+}
+
+func encodePlotCanvas(c vgCanvasWriter) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if _, err := c.WriteTo(buf); err != nil {
+		return nil, fmt.Errorf("failed to encode plot: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderPlot draws p onto a canvas sized in physical units and encodes it in
+// the requested format, replacing the old hard-coded p.WriterTo(vg.Points(N), ..., "png").
+func renderPlot(p *plot.Plot, size PlotSize, format PlotFormat) ([]byte, error) {
+	c, err := newPlotCanvas(size, format)
+	if err != nil {
+		return nil, err
+	}
+	p.Draw(draw.New(c))
+	return encodePlotCanvas(c)
+}
@@ -0,0 +1,77 @@
+package report
+
+import "github.com/jung-kurt/gofpdf"
+
+// newCaptionFrameTemplate defines, once per report, the thin bordered frame
+// stamped under every image's caption (heatmaps and per-bank line plots
+// alike), so every caption in the PDF shares a pixel-identical frame rather
+// than each call site re-specifying its own draw color and line width.
+func newCaptionFrameTemplate(pdf *gofpdf.Fpdf) gofpdf.Template {
+	return pdf.CreateTemplate(func(tpl *gofpdf.Tpl) {
+		tpl.SetDrawColor(150, 150, 150)
+		tpl.SetLineWidth(0.2)
+		tpl.Rect(0, 0, 1, 1, "D")
+	})
+}
+
+// stampCaptionFrame stamps the shared caption-frame template into the box
+// spanning (x, y) to (x+width, y+height). A no-op if the template hasn't
+// been created yet or the box is degenerate.
+func (s *pdfStyler) stampCaptionFrame(x, y, width, height float64) {
+	if s.captionFrameTpl == nil || width <= 0 || height <= 0 {
+		return
+	}
+	s.pdf.UseTemplateScaled(s.captionFrameTpl, gofpdf.PointType{X: x, Y: y}, gofpdf.SizeType{Wd: width, Ht: height})
+}
+
+// setTableHeaderTemplate defines a reusable template for one ranking/out-of-
+// tolerance table's header row (labels, column widths, the grey fill) and
+// immediately stamps it at the styler's current position. Every later
+// checkAddPage-triggered page break re-stamps this same template via
+// repeatTableHeaderOnBreak, so a data row can never land on a fresh page
+// under no header. Callers must call clearTableHeaderTemplate once the table
+// is finished, so a later table's checkAddPage calls don't re-stamp a stale
+// header.
+func (s *pdfStyler) setTableHeaderTemplate(headers []string, colWidthsAbs []float64) {
+	s.tableHeaderHeight = s.lineHeight
+	s.tableHeaderTpl = s.pdf.CreateTemplate(func(tpl *gofpdf.Tpl) {
+		tpl.SetFont("Arial", "B", 9)
+		tpl.SetFillColor(200, 200, 200)
+		tpl.SetTextColor(0, 0, 0)
+		x := 0.0
+		for i, header := range headers {
+			tpl.SetXY(x, 0)
+			tpl.CellFormat(colWidthsAbs[i], s.tableHeaderHeight, header, "1", 0, "C", true, 0, "")
+			x += colWidthsAbs[i]
+		}
+	})
+	s.stampTableHeader()
+}
+
+// clearTableHeaderTemplate stops repeatTableHeaderOnBreak from re-stamping a
+// header once the table it belongs to is finished.
+func (s *pdfStyler) clearTableHeaderTemplate() {
+	s.tableHeaderTpl = nil
+}
+
+// stampTableHeader draws the active table header template at the styler's
+// current Y and advances past it. A no-op when no table header is active.
+func (s *pdfStyler) stampTableHeader() {
+	if s.tableHeaderTpl == nil {
+		return
+	}
+	// The template is page-sized (CreateTemplate), so stamp it at its own
+	// size: the header row is drawn at scale 1 from the given corner.
+	_, tplSize := s.tableHeaderTpl.Size()
+	s.pdf.UseTemplateScaled(s.tableHeaderTpl, gofpdf.PointType{X: s.marginMM, Y: s.currentY}, tplSize)
+	s.currentY += s.tableHeaderHeight
+}
+
+// repeatTableHeaderOnBreak is checkAddPage's post-page-break hook: whenever a
+// table header template is active, it re-stamps that same header row at the
+// top of the page checkAddPage just started. This replaces the old behavior,
+// where a row-by-row checkAddPage call inside a table's render loop could
+// push a data row onto a fresh page with no header at all.
+func (s *pdfStyler) repeatTableHeaderOnBreak() {
+	s.stampTableHeader()
+}
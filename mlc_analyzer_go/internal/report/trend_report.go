@@ -0,0 +1,337 @@
+package report
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/user/mlc_analyzer_go/internal/analysis"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// DatedAnalysis pairs one CSV session's analysis results with the date the
+// session was recorded, the input to BuildTrendReport's longitudinal view
+// across multiple QA sessions.
+type DatedAnalysis struct {
+	Date    time.Time
+	Source  string // base filename the session was parsed from
+	Results *analysis.AnalysisResults
+}
+
+// trendLeafKey identifies one leaf across sessions for longitudinal tracking.
+type trendLeafKey struct {
+	BankName string
+	LeafID   string
+}
+
+// trendPoint is one session's deviation reading for a leaf.
+type trendPoint struct {
+	Date      time.Time
+	Deviation float64
+	OutOfTol  bool
+}
+
+// buildTrendSeries groups every session's per-leaf deviation readings by
+// leaf, so BuildTrendReport can look at a single leaf's history across all
+// sessions without re-scanning each session's results.
+func buildTrendSeries(sessions []DatedAnalysis) map[trendLeafKey][]trendPoint {
+	series := make(map[trendLeafKey][]trendPoint)
+	for _, session := range sessions {
+		if session.Results == nil {
+			continue
+		}
+		for _, res := range session.Results.Results {
+			key := trendLeafKey{BankName: res.BankName, LeafID: res.LeafID}
+			series[key] = append(series[key], trendPoint{
+				Date:      session.Date,
+				Deviation: res.Deviation,
+				OutOfTol:  res.IsOutOfTolerance,
+			})
+		}
+	}
+	return series
+}
+
+// buildTrendLinePlot plots every leaf of bankName as one line of deviation
+// across sessionDates, mirroring buildLinePlot's per-bank layout but with
+// session date (rather than leaf number) on the X axis.
+func buildTrendLinePlot(bankName string, series map[trendLeafKey][]trendPoint, sessionDates []time.Time, toleranceMM float64) (*plot.Plot, error) {
+	p := plot.New()
+	p.Title.Text = fmt.Sprintf("%s Bank Deviation Trend", bankName)
+	p.X.Label.Text = "Session"
+	p.Y.Label.Text = "Mean Deviation (mm)"
+	p.Add(plotter.NewGrid())
+
+	ticks := make([]plot.Tick, len(sessionDates))
+	for i, d := range sessionDates {
+		ticks[i] = plot.Tick{Value: float64(i), Label: d.Format("2006-01-02")}
+	}
+	p.X.Tick.Marker = plot.ConstantTicks(ticks)
+	p.X.Min = 0
+	p.X.Max = float64(len(sessionDates) - 1)
+
+	if toleranceMM != 0 {
+		tolLinePos, _ := plotter.NewLine(plotter.XYs{{X: p.X.Min, Y: toleranceMM}, {X: p.X.Max, Y: toleranceMM}})
+		tolLinePos.Color = color.RGBA{R: 255, A: 255}
+		tolLinePos.LineStyle.DashArray = []vg.Length{vg.Points(5), vg.Points(5)}
+		p.Add(tolLinePos)
+
+		tolLineNeg, _ := plotter.NewLine(plotter.XYs{{X: p.X.Min, Y: -toleranceMM}, {X: p.X.Max, Y: -toleranceMM}})
+		tolLineNeg.Color = color.RGBA{R: 255, A: 255}
+		tolLineNeg.LineStyle.DashArray = []vg.Length{vg.Points(5), vg.Points(5)}
+		p.Add(tolLineNeg)
+	}
+
+	dateIndex := make(map[time.Time]int, len(sessionDates))
+	for i, d := range sessionDates {
+		dateIndex[d] = i
+	}
+
+	keys := make([]trendLeafKey, 0)
+	for key := range series {
+		if key.BankName == bankName {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].LeafID < keys[j].LeafID })
+
+	plotColors := DefaultPaletteRegistry.Qualitative(PaletteDefault)
+	colorIndex := 0
+	linesPlotted := false
+
+	for _, key := range keys {
+		pts := make(plotter.XYs, 0, len(sessionDates))
+		for _, pt := range series[key] {
+			if math.IsNaN(pt.Deviation) {
+				continue
+			}
+			pts = append(pts, plotter.XY{X: float64(dateIndex[pt.Date]), Y: pt.Deviation})
+		}
+		if len(pts) == 0 {
+			continue
+		}
+		sort.Slice(pts, func(i, j int) bool { return pts[i].X < pts[j].X })
+
+		line, err := plotter.NewLine(pts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trend line for leaf %s: %w", key.LeafID, err)
+		}
+		line.Color = plotColors[colorIndex%len(plotColors)]
+		line.LineStyle.Width = vg.Points(1)
+		p.Add(line)
+		colorIndex++
+		linesPlotted = true
+	}
+
+	if !linesPlotted {
+		return nil, fmt.Errorf("no deviation data for bank %s across any session", bankName)
+	}
+	return p, nil
+}
+
+// BuildTrendReport creates a longitudinal PDF summarizing deviation trends
+// for every leaf across multiple dated QA sessions: a table of leaves out of
+// tolerance in at least minPersistentSessions sessions, a table of leaves
+// whose tolerance status changed between their first and last session, and
+// per-bank deviation-over-time line plots. meta is printed in the
+// header/footer of every page as in BuildPDFReport.
+func BuildTrendReport(filepath string, sessions []DatedAnalysis, toleranceMM float64, minPersistentSessions int, meta ReportMeta) error {
+	if len(sessions) == 0 {
+		return fmt.Errorf("no sessions to report on")
+	}
+
+	sorted := make([]DatedAnalysis, len(sessions))
+	copy(sorted, sessions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	sessionDates := make([]time.Time, len(sorted))
+	for i, s := range sorted {
+		sessionDates[i] = s.Date
+	}
+
+	series := buildTrendSeries(sorted)
+
+	pageConfig := PageConfig{}.withDefaults()
+	pdf := gofpdf.New(pageConfig.Orientation, "mm", pageConfig.Size, "")
+	pdf.SetMargins(pageConfig.MarginMM, pageConfig.MarginMM, pageConfig.MarginMM)
+
+	styler := newPDFStyler(pdf, pageConfig.MarginMM)
+	styler.installHeaderFooter(meta, toleranceMM)
+	pdf.AddPage()
+
+	pdf.Bookmark("Summary", 0, -1)
+	styler.writeParagraph(fmt.Sprintf("MLC Longitudinal Trend Report (%d Sessions)", len(sorted)), "h1", "C")
+	styler.addSpacer(5)
+	styler.writeParagraph(fmt.Sprintf("Sessions: %s to %s", sessionDates[0].Format("2006-01-02"), sessionDates[len(sessionDates)-1].Format("2006-01-02")), "normal", "L")
+	styler.addSpacer(5)
+
+	pdf.Bookmark("Persistently Out-of-Tolerance Leaves", 0, -1)
+	styler.writeParagraph(fmt.Sprintf("Leaves Out of Tolerance in >= %d Sessions", minPersistentSessions), "h2", "L")
+
+	type persistentRow struct {
+		Key           trendLeafKey
+		OutOfTolCount int
+	}
+	var persistent []persistentRow
+	for key, points := range series {
+		count := 0
+		for _, pt := range points {
+			if pt.OutOfTol {
+				count++
+			}
+		}
+		if count >= minPersistentSessions {
+			persistent = append(persistent, persistentRow{Key: key, OutOfTolCount: count})
+		}
+	}
+	sort.Slice(persistent, func(i, j int) bool {
+		if persistent[i].OutOfTolCount != persistent[j].OutOfTolCount {
+			return persistent[i].OutOfTolCount > persistent[j].OutOfTolCount
+		}
+		return persistent[i].Key.LeafID < persistent[j].Key.LeafID
+	})
+
+	if len(persistent) > 0 {
+		headers := []string{"Bank", "Leaf ID", "Sessions Out of Tolerance"}
+		colWidthsRel := []float64{0.3, 0.3, 0.4}
+		colWidthsAbs := make([]float64, len(colWidthsRel))
+		for i, rel := range colWidthsRel {
+			colWidthsAbs[i] = rel * styler.contentWidth
+		}
+
+		tableHeightNeeded := styler.lineHeight * (float64(len(persistent)) + 1.0)
+		styler.checkAddPage(tableHeightNeeded)
+
+		styler.setTableHeaderTemplate(headers, colWidthsAbs)
+
+		for _, row := range persistent {
+			rowData := []string{row.Key.BankName, row.Key.LeafID, fmt.Sprintf("%d / %d", row.OutOfTolCount, len(sorted))}
+			styler.checkAddPage(styler.lineHeight) // re-stamps the header on a break
+			sY := styler.currentY
+			sX := styler.marginMM
+			styler.applyStyle("tableCellRed")
+			for i, cellData := range rowData {
+				styler.pdf.SetXY(sX, sY)
+				styler.pdf.CellFormat(colWidthsAbs[i], styler.lineHeight, cellData, "1", 0, "C", false, 0, "")
+				sX += colWidthsAbs[i]
+			}
+			sY += styler.lineHeight
+			styler.currentY = sY
+		}
+		styler.clearTableHeaderTemplate()
+	} else {
+		styler.writeParagraph("No leaves were out of tolerance in enough sessions to qualify.", "normal", "L")
+	}
+	styler.addSpacer(5)
+
+	pdf.Bookmark("Tolerance Status Changes (First vs. Last Session)", 0, -1)
+	styler.writeParagraph("Tolerance Status Changes (First vs. Last Session)", "h2", "L")
+
+	type deltaRow struct {
+		Key      trendLeafKey
+		FirstOOT bool
+		LastOOT  bool
+	}
+	var deltas []deltaRow
+	for key, points := range series {
+		if len(points) == 0 {
+			continue
+		}
+		sortedPoints := make([]trendPoint, len(points))
+		copy(sortedPoints, points)
+		sort.Slice(sortedPoints, func(i, j int) bool { return sortedPoints[i].Date.Before(sortedPoints[j].Date) })
+		first := sortedPoints[0]
+		last := sortedPoints[len(sortedPoints)-1]
+		if first.OutOfTol != last.OutOfTol {
+			deltas = append(deltas, deltaRow{Key: key, FirstOOT: first.OutOfTol, LastOOT: last.OutOfTol})
+		}
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].Key.LeafID < deltas[j].Key.LeafID })
+
+	statusLabel := func(oot bool) string {
+		if oot {
+			return "Out of Tolerance"
+		}
+		return "Within Tolerance"
+	}
+
+	if len(deltas) > 0 {
+		headers := []string{"Bank", "Leaf ID", "First Session", "Last Session"}
+		colWidthsRel := []float64{0.25, 0.25, 0.25, 0.25}
+		colWidthsAbs := make([]float64, len(colWidthsRel))
+		for i, rel := range colWidthsRel {
+			colWidthsAbs[i] = rel * styler.contentWidth
+		}
+
+		tableHeightNeeded := styler.lineHeight * (float64(len(deltas)) + 1.0)
+		styler.checkAddPage(tableHeightNeeded)
+
+		styler.setTableHeaderTemplate(headers, colWidthsAbs)
+
+		for _, row := range deltas {
+			rowData := []string{row.Key.BankName, row.Key.LeafID, statusLabel(row.FirstOOT), statusLabel(row.LastOOT)}
+			styler.checkAddPage(styler.lineHeight) // re-stamps the header on a break
+			sY := styler.currentY
+			sX := styler.marginMM
+			for i, cellData := range rowData {
+				styler.pdf.SetXY(sX, sY)
+				if i == 3 && row.LastOOT {
+					styler.applyStyle("tableCellRed")
+				} else {
+					styler.applyStyle("tableCell")
+				}
+				styler.pdf.CellFormat(colWidthsAbs[i], styler.lineHeight, cellData, "1", 0, "C", false, 0, "")
+				sX += colWidthsAbs[i]
+			}
+			sY += styler.lineHeight
+			styler.currentY = sY
+		}
+		styler.clearTableHeaderTemplate()
+	} else {
+		styler.writeParagraph("No leaves changed tolerance status between the first and last session.", "normal", "L")
+	}
+	styler.addSpacer(5)
+
+	pdf.Bookmark("Deviation Trends", 0, -1)
+	styler.pdf.AddPage()
+	styler.currentY = styler.contentTopY
+	styler.writeParagraph("Deviation Trends", "h1", "C")
+	styler.addSpacer(5)
+
+	bankNameSet := make(map[string]bool)
+	for key := range series {
+		bankNameSet[key.BankName] = true
+	}
+	bankNames := make([]string, 0, len(bankNameSet))
+	for name := range bankNameSet {
+		bankNames = append(bankNames, name)
+	}
+	sort.Strings(bankNames)
+
+	trendImgWidth := styler.contentWidth * 0.8
+	trendImgHeight := trendImgWidth * (DefaultLinePlotSize.HeightMM / DefaultLinePlotSize.WidthMM)
+
+	for _, bankName := range bankNames {
+		plotP, err := buildTrendLinePlot(bankName, series, sessionDates, toleranceMM)
+		if err != nil {
+			styler.writeParagraph(fmt.Sprintf("No trend plot for %s Bank: %v", bankName, err), "normal", "L")
+			continue
+		}
+		imgBytes, err := renderPlot(plotP, DefaultLinePlotSize, PlotFormatPNG)
+		if err != nil {
+			styler.writeParagraph(fmt.Sprintf("Failed to render trend plot for %s Bank: %v", bankName, err), "normal", "L")
+			continue
+		}
+		styler.writeParagraph(fmt.Sprintf("%s Bank", bankName), "h2", "L")
+		styler.addImage(imgBytes, fmt.Sprintf("trend_%s", bankName), trendImgWidth, trendImgHeight,
+			fmt.Sprintf("%s Bank Deviation Trend Across Sessions", bankName), "normal")
+	}
+
+	return pdf.OutputFileAndClose(filepath)
+}